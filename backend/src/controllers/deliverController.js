@@ -63,6 +63,8 @@ async function mark_as_delivered(req, res) {
 
         // Descontamos el stock en memoria
         prod.stock -= it.qty;
+        // Liberamos la reserva que hizo el checkout (pedidos viejos pueden no tenerla → nunca bajamos de 0)
+        prod.reserved = Math.max(0, (prod.reserved || 0) - it.qty);
         // Guardamos el cambio dentro de la transacción
         await prod.save({ session });
 
@@ -87,6 +89,7 @@ async function mark_as_delivered(req, res) {
         email: order.email,
         delivered_at: now,              // Timestamp exacto de la entrega
         status_at_delivery: 'entregado',// Etiqueta fija para claridad histórica
+        access_token_hash: order.access_token_hash, // El comprador sigue accediendo a /status/ con su token
         stock_delta,                    // Qué stock se descontó de cada producto
         day, month, year                // Campos de fecha desnormalizados para analytics
       }], { session });
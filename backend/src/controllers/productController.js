@@ -3,6 +3,9 @@
 // Importamos el modelo Product para manipular los documentos en MongoDB
 const Product = require('../models/Product');
 
+// Categorías fijas del catálogo (para el <select> del form y para validar)
+const { CATEGORIES, CATEGORY_SLUGS } = require('../models/categories');
+
 // Importamos funciones CSRF: para generar tokens nuevos y verificar los que llegan del form
 const { generate_csrf_token, verify_and_consume_csrf_token } = require('../middleware/csrf');
 
@@ -30,7 +33,8 @@ async function new_product_form(req, res) {
     csrf_token: generate_csrf_token(),
     admin_email: res.locals.admin_claims?.email || '',
     mode: 'create',                              // Modo “create” lo usa la vista para los textos/botones
-    product: { name:'', description:'', price:'', stock:'', is_active:true } // Valores iniciales
+    categories: CATEGORIES,                      // Opciones del <select> de categoría
+    product: { name:'', description:'', price:'', stock:'', category:'accesorios', is_active:true } // Valores iniciales
   });
 }
 
@@ -38,7 +42,7 @@ async function new_product_form(req, res) {
 // POST /products — crear producto
 async function create_product(req, res) {
   // Desestructuramos los campos del formulario (body)
-  const { csrf_token, name, description, price, stock, is_active } = req.body;
+  const { csrf_token, name, description, price, stock, category, is_active } = req.body;
 
   // Verificamos token CSRF para seguridad
   if (!verify_and_consume_csrf_token(csrf_token)) {
@@ -50,16 +54,17 @@ async function create_product(req, res) {
   const stock_num = Number(stock);
 
   // Validamos campos mínimos (name requerido, price/stock válidos)
-  if (!name || isNaN(price_num) || isNaN(stock_num) || price_num < 0 || stock_num < 0) {
+  if (!name || isNaN(price_num) || isNaN(stock_num) || price_num < 0 || stock_num < 0 || !CATEGORY_SLUGS.includes(category)) {
     // Si algo falla, re-renderizamos el formulario con un mensaje de error
     return res.status(400).render('products/form', {
       token: res.locals.rotated_token,
       csrf_token: generate_csrf_token(),
       admin_email: res.locals.admin_claims?.email || '',
       mode: 'create',
-      error_msg: 'Campos inválidos (price/stock >= 0, name requerido).',
+      categories: CATEGORIES,
+      error_msg: 'Campos inválidos (price/stock >= 0, name requerido, categoría de la lista).',
       // Repoblamos los valores para que Paula no tenga que reescribirlos
-      product: { name, description, price, stock, is_active: is_active === 'on' }
+      product: { name, description, price, stock, category, is_active: is_active === 'on' }
     });
   }
 
@@ -72,6 +77,7 @@ async function create_product(req, res) {
     description: description || '',
     price: price_num,
     stock: stock_num,
+    category,                      // Slug validado contra categories.js
     is_active: is_active === 'on', // Checkbox -> booleano
     image_path: imgPath,           // Guardamos la imagen si existe
     created_at: new Date(),        // Fecha de creación
@@ -95,6 +101,7 @@ async function edit_product_form(req, res) {
     csrf_token: generate_csrf_token(),
     admin_email: res.locals.admin_claims?.email || '',
     mode: 'edit', // Usado en la vista para mostrar botones/textos correctos
+    categories: CATEGORIES,
     product       // Datos actuales del producto
   });
 }
@@ -103,7 +110,7 @@ async function edit_product_form(req, res) {
 // POST /products/:id — actualizar producto existente
 async function update_product(req, res) {
   const { id } = req.params;
-  const { csrf_token, name, description, price, stock, is_active } = req.body;
+  const { csrf_token, name, description, price, stock, category, is_active } = req.body;
 
   // Validamos CSRF
   if (!verify_and_consume_csrf_token(csrf_token)) {
@@ -115,7 +122,7 @@ async function update_product(req, res) {
   const stock_num = Number(stock);
 
   // Validamos datos igual que en create
-  if (!name || isNaN(price_num) || isNaN(stock_num) || price_num < 0 || stock_num < 0) {
+  if (!name || isNaN(price_num) || isNaN(stock_num) || price_num < 0 || stock_num < 0 || !CATEGORY_SLUGS.includes(category)) {
     // Recuperamos el producto actual para re-renderizarlo con el error
     const product_again = await Product.findById(id);
     return res.status(400).render('products/form', {
@@ -123,13 +130,15 @@ async function update_product(req, res) {
       csrf_token: generate_csrf_token(),
       admin_email: res.locals.admin_claims?.email || '',
       mode: 'edit',
-      error_msg: 'Campos inválidos (price/stock >= 0, name requerido).',
+      categories: CATEGORIES,
+      error_msg: 'Campos inválidos (price/stock >= 0, name requerido, categoría de la lista).',
       product: {
         _id: id,
         name: name || (product_again?.name || ''),
         description,
         price,
         stock,
+        category,
         is_active: is_active === 'on'
       }
     });
@@ -141,6 +150,7 @@ async function update_product(req, res) {
     description: description || '',
     price: price_num,
     stock: stock_num,
+    category,
     is_active: is_active === 'on',
     updated_at: new Date()
   });
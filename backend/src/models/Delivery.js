@@ -20,6 +20,9 @@ const delivered_item_schema = new mongoose.Schema({
   // Precio unitario en el momento de la compra
   unit_price: { type: Number, required: true, min: 0 },
 
+  // Categoría del producto al momento de la compra (snapshot, para reportes por categoría)
+  category:   { type: String, default: '' },
+
   // Subtotal (unit_price * qty)
   subtotal:   { type: Number, required: true, min: 0 }
 
@@ -51,6 +54,9 @@ const delivery_schema = new mongoose.Schema({
   // Estado fijo al momento de entrega (por claridad semántica)
   status_at_delivery: { type: String, default: 'entregado' },
 
+  // Copia del hash del token de acceso del pedido (el comprador sigue viendo su estado)
+  access_token_hash: { type: String, default: '' },
+
 
   // stock_delta: qué se descontó del stock de cada producto
 
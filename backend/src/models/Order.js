@@ -21,6 +21,9 @@ const order_item_schema = new mongoose.Schema({
   // Precio unitario al momento de la compra (snapshot)
   unit_price: { type: Number, required: true, min: 0 },
 
+  // Categoría del producto al momento de la compra (snapshot, para reportes por categoría)
+  category:   { type: String, default: '' },
+
   // Subtotal = qty * unit_price
   subtotal:   { type: Number, required: true, min: 0 }
 
@@ -54,7 +57,11 @@ const order_schema = new mongoose.Schema({
   },
 
   // Fecha de creación del pedido (por defecto: hora actual)
-  created_at: { type: Date, default: Date.now }
+  created_at: { type: Date, default: Date.now },
+
+  // Hash (SHA-256) del token secreto que la tienda Go le da al comprador
+  // para ver/editar su pedido. Nunca se guarda el token en claro.
+  access_token_hash: { type: String, default: '' }
 });
 
 
@@ -4,6 +4,9 @@
 // y modelos para interactuar con MongoDB de forma estructurada.
 const mongoose = require('mongoose');
 
+// Lista fija de categorías (compartida con la tienda Go)
+const { CATEGORY_SLUGS } = require('./categories');
+
 
 // Definición del esquema de producto
 // Este esquema define cómo se guarda cada producto en la base de datos.
@@ -22,6 +25,13 @@ const product_schema = new mongoose.Schema({
   // Stock disponible actualmente
   stock: { type: Number, required: true, min: 0 },
 
+  // Unidades reservadas por pedidos activos (la tienda Go las reserva en el checkout).
+  // Disponible para vender = stock - reserved. Se libera al entregar el pedido.
+  reserved: { type: Number, default: 0, min: 0 },
+
+  // Categoría (slug de categories.js): la tienda la usa para navegar y los pedidos la copian en cada ítem
+  category: { type: String, enum: CATEGORY_SLUGS, default: 'accesorios' },
+
   // Ruta pública a la imagen (por ejemplo: "/uploads/pescado-fresco.png")
   image_path: { type: String, default: '' },
 
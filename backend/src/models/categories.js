@@ -0,0 +1,15 @@
+// categories.js — Categorías fijas del catálogo
+// La tienda Go tiene la misma lista (models.Categories) y la usa en /category/:slug,
+// así que un slug nuevo hay que agregarlo en los dos lados.
+
+const CATEGORIES = [
+  { slug: 'pescados',   name: 'Pescados' },
+  { slug: 'krill',      name: 'Krill' },
+  { slug: 'hielo',      name: 'Bloques de hielo' },
+  { slug: 'accesorios', name: 'Accesorios' }
+];
+
+// Sólo los slugs (para el enum del esquema y para validar el form)
+const CATEGORY_SLUGS = CATEGORIES.map(c => c.slug);
+
+module.exports = { CATEGORIES, CATEGORY_SLUGS };
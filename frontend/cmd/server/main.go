@@ -6,149 +6,185 @@ import (
 	"context"       // context.Context: manejar cancelaciones y timeouts
 	"fmt"           // fmt: formatear strings (usado para números en templates)
 	"html/template" // html/template: motor SSR nativo, seguro ante inyección HTML
-	"log"           // log: registro de eventos y errores
+	"log/slog"      // slog: logs estructurados en JSON
 	"net/http"      // net/http: servidor HTTP estándar
-	"os"            // os: leer variables de entorno (APP_ENV, etc.)
+	"os"            // os.Exit: código de salida si el servidor no arrancó
+	"os/signal"     // signal.NotifyContext: apagado ordenado con SIGTERM/SIGINT
+	"syscall"       // syscall.SIGTERM: la señal que manda Docker al parar el contenedor
 	"time"          // time: duraciones, timeouts y timestamps
 
 	// Paquetes internos del proyecto
+	"github.com/gastonduartem/Challenge-1/frontend/internal/cache"    // cache del catálogo (change stream de products)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/config"   // configuración tipada (entorno, .env, TOML)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"     // token CSRF de los forms POST
 	"github.com/gastonduartem/Challenge-1/frontend/internal/db"       // conexión a MongoDB
 	"github.com/gastonduartem/Challenge-1/frontend/internal/handlers" // controladores HTTP (home, checkout, etc.)
-	"github.com/joho/godotenv"                                        // carga variables desde archivo .env
-	"go.mongodb.org/mongo-driver/bson/primitive"                      // tipos especiales de Mongo (ObjectID)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/live"     // fan-out del change stream de orders (SSE)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/logging"  // logger JSON, request id y access log
+	"github.com/gastonduartem/Challenge-1/frontend/internal/metrics"  // monitor de comandos de Mongo y cache del catálogo (/metrics)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session"  // cookie de sesión firmada (carrito)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"    // repositorios (products, orders, deliveries)
 )
 
+// maxHeaderBytes: tamaño máximo de los headers de una request (cookies incluidas).
+const maxHeaderBytes = 64 << 10 // 64 KB
+
 // FUNCIONES AUXILIARES
 
-// mustEnv → lee una variable de entorno y falla (log.Fatal) si no existe.
-// Se usa para variables que son OBLIGATORIAS en producción.
-func mustEnv(key string) string {
-	v := os.Getenv(key)
-	if v == "" {
-		log.Fatalf("variable de entorno faltante: %s", key)
-	}
-	return v
+// fatal registra msg como error y termina el proceso (slog no tiene Fatal).
+// Igual que log.Fatal, no corre los defer.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
-// getEnv → lee una variable de entorno y, si no existe, devuelve un valor por defecto.
-// Muy útil en desarrollo (para no romper si falta el .env).
-func getEnv(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// templatesLoaded verifica que tmpls tenga cada una de names (chequeo de /readyz).
+func templatesLoaded(tmpls *template.Template, names []string) error {
+	for _, name := range names {
+		if tmpls.Lookup(name) == nil {
+			return fmt.Errorf("falta la plantilla %s", name)
+		}
 	}
-	return def
+	return nil
 }
 
 // FUNCIÓN MAIN
 
 func main() {
-	// Si no estamos en producción, cargamos el archivo .env
-	// (en Docker las variables vienen del entorno, así que no hace falta cargarlo)
-	if os.Getenv("APP_ENV") != "production" {
-		_ = godotenv.Load() // ignora el error si el archivo no existe
-	}
-
 	// CONFIGURACIÓN
-
-	portFrontend := getEnv("PORT_FRONTEND", "8081")                // Puerto del servidor (por defecto 8081)
-	mongoDB := getEnv("MONGO_DB", "penguin_shop")                  // Nombre de la base de datos
-	uploadsBase := getEnv("UPLOADS_BASE", "http://localhost:4100") // URL base para imágenes
-
-	var mongoURI string
-	if os.Getenv("APP_ENV") == "production" {
-		mongoURI = mustEnv("MONGO_URI") // obligatorio en producción
-	} else {
-		mongoURI = getEnv("MONGO_URI", "mongodb://localhost:27017/penguin_shop?replicaSet=rs0")
+	// config.Load: defaults → CONFIG_FILE (TOML) → .env / variables de entorno, todo validado.
+	// Si algo está mal lista todos los problemas juntos y cortamos acá.
+	// Hasta leer LOG_LEVEL logueamos en JSON a nivel Info (los errores de config salen igual).
+	slog.SetDefault(logging.New(os.Stdout, slog.LevelInfo))
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("configuración inválida", "err", err)
 	}
-	log.Println("[debug] MONGO_URI =", mongoURI) // Log de depuración
+	logger := logging.New(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)                                    // slog.ErrorContext & cía. en todo el proceso
+	slog.Info("configuración cargada", "config", cfg.String()) // String() oculta la contraseña de MONGO_URI y SESSION_SECRET
+
+	// appCtx se cancela con SIGTERM (docker stop / deploy) o SIGINT (Ctrl+C): corta los change
+	// streams y arranca el apagado ordenado. Una segunda señal ya mata el proceso de una.
+	appCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// CONEXIÓN A MONGODB
 
 	// Creamos un contexto con timeout de 10 segundos para el handshake inicial
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(appCtx, 10*time.Second) // Ctrl+C también corta la espera
 	defer cancel()
 
 	// db.Connect encapsula mongo.Connect + Ping
-	client, err := db.Connect(ctx, mongoURI)
+	client, err := db.Connect(ctx, cfg.MongoURI, metrics.MongoMonitor()) // el monitor mide cada comando por colección
 	if err != nil {
-		log.Fatalf("[mongo] error: %v", err) // Fatal → corta la ejecución
+		fatal("no se pudo conectar a MongoDB", "err", err) // corta la ejecución
 	}
-	// Al salir, desconectamos para liberar recursos
-	defer func() { _ = client.Disconnect(context.Background()) }()
-	log.Println("✅ Conexión exitosa con MongoDB:", mongoDB)
-
-	// Obtenemos las colecciones de la base de datos
-	database := client.Database(mongoDB)
-	colProducts := database.Collection("products")
-	colOrders := database.Collection("orders")
-	colDeliveries := database.Collection("deliveries")
-
-	// TEMPLATE FUNC MAP
-
-	// FuncMap: mapa de funciones que podemos usar dentro de los templates HTML
-	funcs := template.FuncMap{
-		// "hex": convierte un ObjectID en su representación hexadecimal (24 chars)
-		"hex": func(id primitive.ObjectID) string { return id.Hex() },
-
-		// "fmtNumber": formatea un número como string (para mostrar precios)
-		"fmtNumber": func(n int) string { return fmt.Sprintf("%d", n) },
-
-		// "last4": devuelve los últimos 4 caracteres del ObjectID (ID corto visual)
-		"last4": func(id primitive.ObjectID) string {
-			h := id.Hex()
-			if len(h) >= 4 {
-				return h[len(h)-4:]
-			}
-			return h
-		},
+	slog.Info("conectado a MongoDB", "db", cfg.MongoDB)
+
+	// Armamos los repositorios sobre las colecciones de la base de datos
+	// (los handlers dependen sólo de las interfaces de store, no de *mongo.Collection)
+	stores := store.NewMongo(client.Database(cfg.MongoDB))
+	// Índices que necesita la tienda (TTL de los carritos); es idempotente
+	if err := store.EnsureIndexes(ctx, client.Database(cfg.MongoDB)); err != nil {
+		_ = client.Disconnect(context.Background()) // fatal no corre los defer
+		fatal("no se pudieron crear los índices", "err", err)
 	}
+	sessions := session.NewManager(cfg.SessionSecret) // cookie firmada con el id de sesión (carrito)
+
+	// Un solo change stream sobre "orders" para todo el proceso; el hub lo reparte
+	// entre las conexiones SSE del tablero y de las páginas de estado
+	hub := live.NewHub()
+	go hub.Run(appCtx, stores.OrderFeed)
+
+	// Catálogo en memoria para la home, las categorías y la ficha de producto (al día por
+	// change stream). Checkout, carrito y edición leen de la DB: precios y stock reales.
+	catalog := cache.NewCatalog(stores.Products)
+	go catalog.Run(appCtx, stores.ProductFeed)
+	metrics.RegisterCatalog(func() metrics.CatalogStats { // hits/misses del cache en /metrics
+		s := catalog.Stats()
+		return metrics.CatalogStats{Ready: s.Ready, Products: s.Products, Hits: s.Hits, Misses: s.Misses}
+	})
 
 	// PARSEO DE TEMPLATES
 
-	// ParseFiles: carga y analiza múltiples archivos de plantilla.
-	// template.Must → paniquea si hay error al parsear (útil para detectar errores al inicio).
-	tmpls := template.Must(template.New("").Funcs(funcs).ParseFiles(
-		"internal/templates/home.tmpl",
-		"internal/templates/orders_board.tmpl",
-		"internal/templates/order_status.tmpl",
-		"internal/templates/edit.tmpl",
-	))
-
-	// Lookup obtiene cada subplantilla por nombre exacto
-	homeTmpl := tmpls.Lookup("home.tmpl")
-	ordersTmpl := tmpls.Lookup("orders_board.tmpl")
-	statusTmpl := tmpls.Lookup("order_status.tmpl")
-	editTmpl := tmpls.Lookup("edit.tmpl")
-	// INYECCIÓN DE DEPENDENCIAS
-
-	// Creamos una estructura que agrupa lo que el handler de /orders necesita.
-	deps := &handlers.OrdersDeps{
-		OrdersCol: colOrders,
-		Tpl:       ordersTmpl,
+	// ParseTemplates carga todas las plantillas de internal/templates con sus funciones
+	// (hex, fmtNumber, last4, csrfField); si alguna no parsea, no tiene sentido arrancar.
+	tmpls, err := handlers.ParseTemplates("internal/templates")
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		fatal("no se pudieron parsear las plantillas", "err", err)
 	}
 
 	// DEFINICIÓN DE RUTAS
 
-	http.HandleFunc("/", handlers.NewHome(colProducts, uploadsBase, homeTmpl))
-	http.HandleFunc("/checkout", handlers.NewCheckout(colProducts, colOrders))
-	http.HandleFunc("/orders", deps.OrdersBoard) // handler de panel público de pedidos
-	http.HandleFunc("/status/", handlers.NewStatus(colOrders, colDeliveries, statusTmpl))
-	http.HandleFunc("/edit", handlers.NewEdit(colOrders, editTmpl))
-
-	// Health check → endpoint simple para verificar si el servidor responde
-	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("OK"))
+	// NewRouter registra todas las rutas (patrones con método: "GET /status/{id}") con sus
+	// métricas, y contesta con error.tmpl lo que no matchea (404) o llega con otro método (405)
+	router := handlers.NewRouter(handlers.RouterDeps{
+		Stores:      stores,
+		Catalog:     catalog,
+		Hub:         hub,
+		Sessions:    sessions,
+		CSRF:        csrf.New(cfg.SessionSecret, sessions), // token atado a la sesión; mismo secreto, otro prefijo
+		Templates:   tmpls,
+		UploadsBase: cfg.UploadsBase,
+		// Readiness → puede atender: Mongo, primario del replica set y plantillas (detalle en JSON)
+		Ready: []handlers.ReadyCheck{
+			{Name: "mongo", Run: func(ctx context.Context) error { return db.Ping(ctx, client) }},
+			{Name: "primary", Run: func(ctx context.Context) error { return db.CheckPrimary(ctx, client) }},
+			{Name: "templates", Run: func(context.Context) error { return templatesLoaded(tmpls, handlers.TemplateNames) }},
+		},
 	})
 
 	// ARRANQUE DEL SERVIDOR
 
-	addr := ":" + portFrontend
-	log.Printf("[frontend] escuchando en http://localhost%s", addr)
+	// Sin timeouts un cliente lento puede retener conexiones para siempre
+	// (ReadHeaderTimeout corta slowloris; los streams SSE desactivan Read/WriteTimeout)
+	addr := cfg.Addr()
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           logging.Middleware(router), // request id + access log sobre todas las rutas
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn), // errores de conexión (TLS, headers) también en JSON
+	}
 
-	// http.ListenAndServe bloquea y atiende todas las requests entrantes.
-	// El segundo parámetro nil usa el DefaultServeMux (donde registramos las rutas).
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("error del servidor: %v", err)
+	// ListenAndServe bloquea: lo corremos en una goroutine y esperamos acá un error de
+	// arranque (ej: puerto ocupado) o la señal de apagado
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.ListenAndServe() }()
+	slog.Info("escuchando", "addr", "http://localhost"+addr)
+
+	exitCode := 0
+	select {
+	case err := <-serveErr:
+		slog.Error("error del servidor", "err", err)
+		exitCode = 1
+	case <-appCtx.Done():
+		slog.Info("señal recibida, apagando")
+	}
+	// stop cancela appCtx (si todavía no) → se cierran los change streams y con ellos los
+	// streams SSE abiertos (si no, Shutdown esperaría a que el navegador los corte)
+	stop()
+
+	// Shutdown deja de aceptar conexiones y espera a que terminen las requests en curso;
+	// recién después desconectamos Mongo (las requests que quedaban todavía lo usan)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("no terminaron todas las requests", "err", err)
+		exitCode = 1
+	}
+	disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDisconnect()
+	if err := client.Disconnect(disconnectCtx); err != nil {
+		slog.Warn("error al desconectar MongoDB", "err", err)
+	}
+	slog.Info("apagado")
+	if exitCode != 0 {
+		os.Exit(exitCode) // os.Exit no corre los defer: ya está todo cerrado
 	}
 }
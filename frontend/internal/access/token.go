@@ -0,0 +1,42 @@
+// token.go — Tokens de acceso del comprador a su pedido
+// Al hacer checkout se genera un token secreto aleatorio; el comprador lo recibe
+// en el link de confirmación y en la DB sólo se guarda su hash (SHA-256),
+// así un dump de "orders" no alcanza para editar pedidos ajenos.
+
+package access
+
+import (
+	"crypto/rand"     // fuente aleatoria criptográficamente segura
+	"crypto/sha256"   // hash del token para guardar en Mongo
+	"crypto/subtle"   // comparación en tiempo constante
+	"encoding/base64" // token apto para URL
+	"encoding/hex"    // hash legible en el documento
+)
+
+// tokenBytes: 32 bytes aleatorios → 256 bits, imposible de adivinar por fuerza bruta.
+const tokenBytes = 32
+
+// New genera un token nuevo y devuelve (token para el comprador, hash para guardar).
+func New() (token, hash string, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, Hash(token), nil
+}
+
+// Hash devuelve el SHA-256 en hex del token (lo que se persiste como access_token_hash).
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Match indica si token corresponde al hash guardado.
+// Un hash vacío (pedidos anteriores a los tokens) nunca matchea.
+func Match(hash, token string) bool {
+	if hash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(Hash(token))) == 1
+}
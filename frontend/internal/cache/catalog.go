@@ -0,0 +1,239 @@
+// catalog.go — cache en memoria del catálogo (productos activos)
+// Carga los productos activos al arrancar y los mantiene al día con el change stream de
+// "products" (altas, ediciones del admin, stock y reservas de los pedidos). La home, las
+// categorías y la ficha de producto leen de acá; el checkout, el carrito y la edición de
+// pedidos siguen yendo a la DB (precios y stock de verdad, reservas atómicas).
+
+package cache
+
+import (
+	"context"     // cancelación del feed y timeout de la carga
+	"log/slog"    // slog: cortes del stream / errores de carga
+	"sort"        // ListActive en orden fijo
+	"sync"        // sync.RWMutex: lecturas concurrentes desde los handlers
+	"sync/atomic" // contadores de hits/misses sin lock
+	"time"        // backoff y timeout de la carga
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models" // Product
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"  // ProductStore / ProductFeed / SearchProducts
+	"go.mongodb.org/mongo-driver/bson/primitive"                    // ObjectID
+)
+
+// loadTimeout: tiempo máximo de la carga completa del catálogo.
+const loadTimeout = 10 * time.Second
+
+// Reintentos del feed: arranca en minBackoff y se duplica hasta maxBackoff.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Catalog implementa store.ProductStore sobre una copia en memoria de los productos activos.
+// Mientras no está al día (antes de la primera carga o con el stream cortado) cada lectura
+// va a la DB y cuenta como miss.
+type Catalog struct {
+	db store.ProductStore // fuente de verdad: carga completa y fallback
+
+	mu       sync.RWMutex
+	products map[primitive.ObjectID]models.Product // sólo activos
+	ready    bool                                  // true si products refleja la DB
+	stale    bool                                  // la última carga falló: recargar al reabrir aunque se retome
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Stats: contadores del cache (se publican en /metrics).
+type Stats struct {
+	Ready    bool
+	Products int
+	Hits     int64
+	Misses   int64
+}
+
+// NewCatalog crea el cache vacío sobre db; se llena cuando se llama a Run.
+func NewCatalog(db store.ProductStore) *Catalog {
+	return &Catalog{db: db, products: map[primitive.ObjectID]models.Product{}}
+}
+
+// Stats devuelve el estado actual del cache.
+func (c *Catalog) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Stats{Ready: c.ready, Products: len(c.products), Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// Run sigue feed hasta que ctx se cancele. Cada vez que el stream abre sin poder retomar
+// (la primera vez, o si el resume token venció) recarga todo desde la DB, reintentando si la
+// carga falla; si se corta,
+// el cache deja de responder (todo a la DB) hasta que vuelva a abrir, con backoff exponencial.
+func (c *Catalog) Run(ctx context.Context, feed store.ProductFeed) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := feed.Watch(ctx, func(ch store.ProductChange) { c.apply(ctx, ch) })
+		c.setReady(false)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("se cortó el change stream de products", "err", err, "retry_in", backoff.String())
+
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff // venía andando bien: reintento rápido
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// apply aplica un evento del feed.
+func (c *Catalog) apply(ctx context.Context, ch store.ProductChange) {
+	if ch.Opened {
+		c.mu.RLock()
+		stale := c.stale
+		c.mu.RUnlock()
+		if ch.Resumed && !stale {
+			c.setReady(true) // no se perdió nada: lo que teníamos sigue valiendo
+			return
+		}
+		c.load(ctx)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ch.Product == nil || !ch.Product.Active {
+		delete(c.products, ch.ProductID)
+		return
+	}
+	c.products[ch.ProductID] = *ch.Product
+}
+
+// load recarga el catálogo y, si falla con el stream abierto, reintenta con backoff
+// exponencial hasta lograrlo o hasta que ctx se cancele. Mientras tanto el cache no sirve
+// (todo a la DB) y los eventos del stream esperan: la carga que sale bien ya los incluye.
+func (c *Catalog) load(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		err := c.reload(ctx)
+		if err == nil {
+			return
+		}
+		slog.Error("no se pudo cargar el catálogo", "err", err, "retry_in", backoff.String())
+		c.mu.Lock()
+		c.stale = true // sin servir hasta la próxima carga (también si el stream se corta antes)
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// reload reemplaza el contenido por los productos activos de la DB.
+// La lectura se hace sin el lock (el feed en memoria avisa cambios con su propio lock tomado).
+func (c *Catalog) reload(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	list, err := c.db.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	products := make(map[primitive.ObjectID]models.Product, len(list))
+	for _, p := range list {
+		products[p.ID] = p
+	}
+
+	c.mu.Lock()
+	c.products, c.ready, c.stale = products, true, false
+	c.mu.Unlock()
+	slog.Info("catálogo cargado", "products", len(products))
+	return nil
+}
+
+func (c *Catalog) setReady(ready bool) {
+	c.mu.Lock()
+	c.ready = ready && !c.stale
+	c.mu.Unlock()
+}
+
+// snapshot copia los productos activos si el cache está al día (ok = false → ir a la DB).
+func (c *Catalog) snapshot() ([]models.Product, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.ready {
+		return nil, false
+	}
+	out := make([]models.Product, 0, len(c.products))
+	for _, p := range c.products {
+		out = append(out, p)
+	}
+	return out, true
+}
+
+// ListActive implementa store.ProductStore (ordenados por _id, como el store en memoria).
+func (c *Catalog) ListActive(ctx context.Context) ([]models.Product, error) {
+	list, ok := c.snapshot()
+	if !ok {
+		c.misses.Add(1)
+		return c.db.ListActive(ctx)
+	}
+	c.hits.Add(1)
+	sort.Slice(list, func(i, j int) bool { return list[i].ID.Hex() < list[j].ID.Hex() })
+	return list, nil
+}
+
+// Search implementa store.ProductStore. Las búsquedas de texto van siempre a la DB: las
+// responde el índice de texto de Mongo (relevancia, raíces de palabras), que el cache no
+// replica. El resto de los filtros (categoría, precio, stock, orden) salen del cache con
+// store.SearchProducts.
+func (c *Catalog) Search(ctx context.Context, q store.CatalogQuery) (store.CatalogPage, error) {
+	list, ok := c.snapshot()
+	if !ok || q.Text != "" {
+		c.misses.Add(1)
+		return c.db.Search(ctx, q)
+	}
+	c.hits.Add(1)
+	return store.SearchProducts(list, q), nil
+}
+
+// FindByID implementa store.ProductStore. Los productos inactivos no están en el cache:
+// se buscan en la DB (miss) para que el llamador decida qué hacer con ellos.
+func (c *Catalog) FindByID(ctx context.Context, id primitive.ObjectID) (models.Product, error) {
+	c.mu.RLock()
+	p, ok := c.products[id]
+	ok = ok && c.ready
+	c.mu.RUnlock()
+	if !ok {
+		c.misses.Add(1)
+		return c.db.FindByID(ctx, id)
+	}
+	c.hits.Add(1)
+	return p, nil
+}
+
+// FindByIDs implementa store.ProductStore: del cache si están todos, si no de la DB.
+func (c *Catalog) FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Product, error) {
+	c.mu.RLock()
+	out := make(map[primitive.ObjectID]models.Product, len(ids))
+	for _, id := range ids {
+		if p, ok := c.products[id]; ok {
+			out[id] = p
+		}
+	}
+	ok := c.ready && len(out) == len(ids)
+	c.mu.RUnlock()
+	if !ok {
+		c.misses.Add(1)
+		return c.db.FindByIDs(ctx, ids)
+	}
+	c.hits.Add(1)
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"
+)
+
+// flakyProducts falla las primeras fails cargas completas (ListActive).
+type flakyProducts struct {
+	store.ProductStore
+	fails atomic.Int32
+}
+
+func (f *flakyProducts) ListActive(ctx context.Context) ([]models.Product, error) {
+	if f.fails.Add(-1) >= 0 {
+		return nil, errors.New("mongo caído")
+	}
+	return f.ProductStore.ListActive(ctx)
+}
+
+func TestCatalogRetriesFailedLoad(t *testing.T) {
+	mem := store.NewMemory()
+	mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 10, Active: true})
+	db := &flakyProducts{ProductStore: mem.Stores().Products}
+	db.fails.Store(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewCatalog(db)
+	go c.Run(ctx, mem.Stores().ProductFeed) // el stream en memoria no se corta nunca
+
+	deadline := time.Now().Add(3 * minBackoff)
+	for !c.Stats().Ready {
+		if time.Now().After(deadline) {
+			t.Fatal("el catálogo no se recargó tras la carga fallida")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st := c.Stats(); st.Products != 1 {
+		t.Errorf("productos en cache = %d, quiero 1", st.Products)
+	}
+}
+
+// searchCounter cuenta las búsquedas que llegan a la DB.
+type searchCounter struct {
+	store.ProductStore
+	searches int
+}
+
+func (s *searchCounter) Search(ctx context.Context, q store.CatalogQuery) (store.CatalogPage, error) {
+	s.searches++
+	return s.ProductStore.Search(ctx, q)
+}
+
+func TestCatalogTextSearchGoesToDB(t *testing.T) {
+	mem := store.NewMemory()
+	mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 10, Active: true})
+	db := &searchCounter{ProductStore: mem.Stores().Products}
+	c := NewCatalog(db)
+	if err := c.reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Search(context.Background(), store.CatalogQuery{Page: 1, PageSize: 10}); err != nil || db.searches != 0 {
+		t.Fatalf("búsqueda sin texto: %d a la DB, err %v; quiero el cache", db.searches, err)
+	}
+	page, err := c.Search(context.Background(), store.CatalogQuery{Text: "sardinas", Page: 1, PageSize: 10})
+	if err != nil || db.searches != 1 {
+		t.Fatalf("búsqueda de texto: %d a la DB, err %v; quiero 1", db.searches, err)
+	}
+	if page.Total != 1 {
+		t.Errorf("resultados = %d, quiero 1", page.Total)
+	}
+}
@@ -0,0 +1,280 @@
+// config.go — configuración tipada del frontend
+// Orden de carga (lo de más abajo pisa a lo de arriba):
+//  1. valores por defecto (pensados para desarrollo local)
+//  2. archivo TOML opcional (ruta en CONFIG_FILE)
+//  3. variables de entorno; fuera de producción también las del archivo .env
+//
+// Load valida todo junto y devuelve todos los problemas en un solo error, así se corrige
+// la configuración de una vez. Config.String() oculta los secretos (para loguearla).
+
+package config
+
+import (
+	"errors"   // errors.As en Problems
+	"fmt"      // mensajes de validación
+	"log/slog" // nivel de log
+	"net/url"  // validar / ocultar la contraseña de MONGO_URI y UPLOADS_BASE
+	"os"       // os.LookupEnv
+	"strconv"  // puertos
+	"strings"  // strings.Join
+	"time"     // duraciones
+
+	"github.com/BurntSushi/toml" // archivo de configuración opcional
+	"github.com/joho/godotenv"   // carga .env en desarrollo
+)
+
+// Entornos válidos de APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// minSecretLen: largo mínimo de SESSION_SECRET en producción.
+const minSecretLen = 16
+
+// devSessionSecret: secreto por defecto en desarrollo (en producción no se acepta).
+const devSessionSecret = "dev-session-secret"
+
+// exampleSessionSecret: el valor de ejemplo que traía docker-compose.yml (tampoco se acepta
+// en producción: es público).
+const exampleSessionSecret = "cambiame-por-una-clave-larga"
+
+// redacted reemplaza a los secretos cuando se imprime la configuración (igual que url.Redacted).
+const redacted = "xxxxx"
+
+// Config: todo lo que el frontend lee del entorno.
+type Config struct {
+	Env           string `toml:"app_env"`        // APP_ENV: "development" o "production"
+	Port          int    `toml:"port"`           // PORT_FRONTEND
+	MongoURI      string `toml:"mongo_uri"`      // MONGO_URI (secreto: puede llevar usuario y contraseña)
+	MongoDB       string `toml:"mongo_db"`       // MONGO_DB
+	UploadsBase   string `toml:"uploads_base"`   // UPLOADS_BASE: prefijo público de las imágenes
+	SessionSecret string `toml:"session_secret"` // SESSION_SECRET (secreto): firma la cookie de sesión
+
+	LogLevel slog.Level `toml:"log_level"` // LOG_LEVEL: debug, info, warn o error
+
+	ReadHeaderTimeout time.Duration `toml:"read_header_timeout"` // HTTP_READ_HEADER_TIMEOUT
+	ReadTimeout       time.Duration `toml:"read_timeout"`        // HTTP_READ_TIMEOUT
+	WriteTimeout      time.Duration `toml:"write_timeout"`       // HTTP_WRITE_TIMEOUT (los streams SSE no lo usan)
+	IdleTimeout       time.Duration `toml:"idle_timeout"`        // HTTP_IDLE_TIMEOUT
+	ShutdownTimeout   time.Duration `toml:"shutdown_timeout"`    // SHUTDOWN_TIMEOUT: espera de requests en curso al apagar
+}
+
+// Production indica si APP_ENV = production.
+func (c Config) Production() bool { return c.Env == EnvProduction }
+
+// Addr: dirección de escucha del http.Server (":<puerto>").
+func (c Config) Addr() string { return ":" + strconv.Itoa(c.Port) }
+
+// String imprime la configuración con los secretos ocultos (la contraseña de MONGO_URI y
+// SESSION_SECRET). Es lo que usan log.Printf("%v") y fmt.Sprint.
+func (c Config) String() string {
+	return fmt.Sprintf("app_env=%s port=%d mongo_uri=%s mongo_db=%s uploads_base=%s session_secret=%s log_level=%s "+
+		"read_header_timeout=%s read_timeout=%s write_timeout=%s idle_timeout=%s shutdown_timeout=%s",
+		c.Env, c.Port, redactURI(c.MongoURI), c.MongoDB, c.UploadsBase, redactSecret(c.SessionSecret), c.LogLevel,
+		c.ReadHeaderTimeout, c.ReadTimeout, c.WriteTimeout, c.IdleTimeout, c.ShutdownTimeout)
+}
+
+// GoString: %#v también oculta los secretos.
+func (c Config) GoString() string { return "config.Config{" + c.String() + "}" }
+
+// redactURI oculta la contraseña de una URI (si no se puede parsear, la oculta entera).
+func redactURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted() // user:xxxxx@host
+}
+
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Problems: error de Load con cada problema de la configuración.
+type Problems []string
+
+func (p Problems) Error() string {
+	return "configuración inválida:\n  - " + strings.Join(p, "\n  - ")
+}
+
+// defaults: configuración de desarrollo local.
+func defaults() Config {
+	return Config{
+		Env:               EnvDevelopment,
+		Port:              8081,
+		MongoURI:          "mongodb://localhost:27017/penguin_shop?replicaSet=rs0",
+		MongoDB:           "penguin_shop",
+		UploadsBase:       "http://localhost:4100",
+		SessionSecret:     devSessionSecret,
+		LogLevel:          slog.LevelInfo,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		// Docker manda SIGKILL 10s después del SIGTERM (stop_grace_period en docker-compose.yml)
+		ShutdownTimeout: 10 * time.Second,
+	}
+}
+
+// Load arma la configuración (defaults → CONFIG_FILE → .env / entorno) y la valida.
+// Si algo está mal devuelve Problems con todos los errores juntos.
+func Load() (Config, error) {
+	// Fuera de producción cargamos .env (no pisa variables que ya existan en el entorno)
+	if os.Getenv("APP_ENV") != EnvProduction {
+		_ = godotenv.Load() // ignora el error si el archivo no existe
+	}
+	return load(os.LookupEnv)
+}
+
+// load es Load con el entorno inyectado.
+func load(lookup func(string) (string, bool)) (Config, error) {
+	cfg := defaults()
+	var problems Problems
+
+	uriInFile := false
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		md, fileProblems := readFile(path, &cfg)
+		problems = append(problems, fileProblems...)
+		uriInFile = md.IsDefined("mongo_uri")
+	}
+	problems = append(problems, fromEnv(lookup, &cfg)...)
+
+	// En producción no hay default para la conexión
+	if v, _ := lookup("MONGO_URI"); cfg.Env == EnvProduction && v == "" && !uriInFile {
+		problems = append(problems, "MONGO_URI: obligatoria en producción")
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, problems
+	}
+	return cfg, nil
+}
+
+// readFile pisa cfg con lo que haya en el archivo TOML path. Las claves desconocidas son error
+// (un typo en el archivo no tiene que pasar desapercibido).
+func readFile(path string, cfg *Config) (toml.MetaData, []string) {
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return md, []string{fmt.Sprintf("CONFIG_FILE %s: %s", path, perr.Message)}
+		}
+		return md, []string{fmt.Sprintf("CONFIG_FILE %s: %v", path, err)}
+	}
+	var problems []string
+	for _, k := range md.Undecoded() {
+		problems = append(problems, fmt.Sprintf("CONFIG_FILE %s: clave desconocida %q", path, k.String()))
+	}
+	return md, problems
+}
+
+// fromEnv pisa cfg con las variables de entorno definidas (y no vacías).
+func fromEnv(lookup func(string) (string, bool), cfg *Config) []string {
+	var problems []string
+	str := func(key string, dst *string) {
+		if v, ok := lookup(key); ok && v != "" {
+			*dst = v
+		}
+	}
+	num := func(key string, dst *int) {
+		if v, ok := lookup(key); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q no es un número", key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	dur := func(key string, dst *time.Duration) {
+		if v, ok := lookup(key); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q no es una duración (ej: 15s, 2m)", key, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	str("APP_ENV", &cfg.Env)
+	num("PORT_FRONTEND", &cfg.Port)
+	str("MONGO_URI", &cfg.MongoURI)
+	str("MONGO_DB", &cfg.MongoDB)
+	str("UPLOADS_BASE", &cfg.UploadsBase)
+	str("SESSION_SECRET", &cfg.SessionSecret)
+	if v, ok := lookup("LOG_LEVEL"); ok && v != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(v)); err != nil {
+			problems = append(problems, fmt.Sprintf("LOG_LEVEL: %q no es válido (debug, info, warn o error)", v))
+		}
+	}
+	dur("HTTP_READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout)
+	dur("HTTP_READ_TIMEOUT", &cfg.ReadTimeout)
+	dur("HTTP_WRITE_TIMEOUT", &cfg.WriteTimeout)
+	dur("HTTP_IDLE_TIMEOUT", &cfg.IdleTimeout)
+	dur("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout)
+	return problems
+}
+
+// validate revisa cada campo y devuelve todos los problemas (vacío = todo bien).
+func (c Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		add("APP_ENV: %q no es válido (development o production)", c.Env)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		add("PORT_FRONTEND: %d fuera de rango (1-65535)", c.Port)
+	}
+
+	if u, err := url.Parse(c.MongoURI); err != nil || (u.Scheme != "mongodb" && u.Scheme != "mongodb+srv") || u.Host == "" {
+		// no mostramos la URI: puede tener la contraseña
+		add("MONGO_URI: no es una URI de Mongo válida (mongodb://host:puerto/...)")
+	}
+	if c.MongoDB == "" || strings.ContainsAny(c.MongoDB, `/\. "$`) {
+		add("MONGO_DB: %q no es un nombre de base válido", c.MongoDB)
+	}
+
+	// UPLOADS_BASE: URL http(s) absoluta o un path del mismo sitio ("/uploads")
+	if u, err := url.Parse(c.UploadsBase); err != nil ||
+		!((u.Scheme == "http" || u.Scheme == "https") && u.Host != "" || u.Scheme == "" && strings.HasPrefix(c.UploadsBase, "/")) {
+		add("UPLOADS_BASE: %q no es una URL http(s) ni un path absoluto", c.UploadsBase)
+	}
+
+	if c.Env == EnvProduction {
+		switch {
+		case c.SessionSecret == "" || c.SessionSecret == devSessionSecret:
+			add("SESSION_SECRET: obligatoria en producción")
+		case c.SessionSecret == exampleSessionSecret:
+			add("SESSION_SECRET: es el valor de ejemplo, generá una clave propia")
+		case len(c.SessionSecret) < minSecretLen:
+			add("SESSION_SECRET: muy corta (mínimo %d caracteres)", minSecretLen)
+		}
+	} else if c.SessionSecret == "" {
+		add("SESSION_SECRET: no puede estar vacía")
+	}
+
+	for _, d := range []struct {
+		key string
+		val time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", c.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", c.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+	} {
+		if d.val <= 0 {
+			add("%s: tiene que ser mayor que 0 (es %s)", d.key, d.val)
+		}
+	}
+	if c.ReadHeaderTimeout > c.ReadTimeout {
+		add("HTTP_READ_HEADER_TIMEOUT (%s) no puede ser mayor que HTTP_READ_TIMEOUT (%s)", c.ReadHeaderTimeout, c.ReadTimeout)
+	}
+	return problems
+}
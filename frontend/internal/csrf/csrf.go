@@ -0,0 +1,120 @@
+// csrf.go — protección CSRF de los forms POST de la tienda (token atado a la sesión)
+// El token de los forms es la firma HMAC del id de sesión, que viaja en la cookie firmada
+// pingu_session (HttpOnly). Un POST sólo pasa si el campo coincide con la firma de la sesión
+// que vino en la request: otro sitio puede hacer que el navegador mande la cookie, pero no
+// puede leer el id ni calcular la firma sin el secreto del servidor. Plantar una cookie
+// tampoco alcanza: tiene que ser una sesión firmada por el servidor, y en ese caso el
+// comprador sólo estaría operando sobre el carrito anónimo del atacante, no al revés.
+
+package csrf
+
+import (
+	"context"         // el estado de la request viaja en el context
+	"crypto/hmac"     // firma del id de sesión
+	"crypto/sha256"   // HMAC-SHA256
+	"encoding/base64" // firma apta para el form
+	"html/template"   // template.HTML: el campo oculto listo para la plantilla
+	"log/slog"        // rechazos (con el id de la request)
+	"net/http"        // middleware
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session" // id de sesión firmado
+)
+
+// FieldName: campo oculto de los forms con la firma.
+const FieldName = "csrf_token"
+
+// HeaderName: alternativa al campo para requests hechas desde JS.
+const HeaderName = "X-CSRF-Token"
+
+// Protector emite y verifica los tokens con un secreto del servidor.
+type Protector struct {
+	secret   []byte
+	sessions *session.Manager
+}
+
+// New crea un Protector sobre las sesiones de sessions. Con el mismo SESSION_SECRET que la
+// cookie de sesión alcanza: las firmas llevan otro prefijo, así la firma de la cookie nunca
+// sirve como token CSRF.
+func New(secret string, sessions *session.Manager) *Protector {
+	return &Protector{secret: []byte(secret), sessions: sessions}
+}
+
+// sign devuelve el token del form para el id de sesión.
+func (p *Protector) sign(sid string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte("csrf:" + sid))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type ctxKey struct{}
+
+// state: lo que Token necesita de la request (la sesión se crea recién si una página la pide).
+type state struct {
+	p   *Protector
+	w   http.ResponseWriter
+	sid string // id de sesión ("" = todavía no hay)
+}
+
+// Protect envuelve next: verifica el token en los métodos que cambian datos (POST, PUT,
+// PATCH, DELETE) y rechaza con reject los que no lo traen o no coinciden. GET y HEAD pasan
+// siempre (no cambian nada) y son los que piden el token para armar los forms.
+func (p *Protector) Protect(next, reject http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := &state{p: p, w: w}
+		if sid, ok := p.sessions.Peek(r); ok {
+			st.sid = sid
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, st))
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			if !p.valid(st.sid, submitted(r)) {
+				slog.WarnContext(r.Context(), "csrf: token inválido", "method", r.Method, "path", r.URL.Path)
+				reject.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// submitted devuelve el token que mandó el cliente: el campo del form o, si no vino, el header.
+func submitted(r *http.Request) string {
+	if tok := r.PostFormValue(FieldName); tok != "" {
+		return tok
+	}
+	return r.Header.Get(HeaderName)
+}
+
+// valid compara (en tiempo constante) el token recibido con la firma del id de sesión.
+func (p *Protector) valid(sid, token string) bool {
+	if sid == "" || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(p.sign(sid)))
+}
+
+// Token devuelve el token para los forms de la respuesta a r. Si el navegador todavía no
+// tiene sesión la crea (Set-Cookie de pingu_session), así que hay que llamarlo antes de
+// escribir el body. Fuera de Protect devuelve "" (los forms quedarían sin token y sus POST
+// se rechazan).
+func Token(r *http.Request) string {
+	st, ok := r.Context().Value(ctxKey{}).(*state)
+	if !ok {
+		return ""
+	}
+	if st.sid == "" {
+		sid, err := st.p.sessions.ID(st.w, r)
+		if err != nil {
+			return ""
+		}
+		st.sid = sid // una sola sesión nueva aunque la página pida el token varias veces
+	}
+	return st.p.sign(st.sid)
+}
+
+// Field arma el campo oculto con token (función "csrfField" de las plantillas).
+func Field(token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + FieldName + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
@@ -0,0 +1,61 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session"
+)
+
+const testSecret = "secreto-de-prueba-bastante-largo"
+
+// issue pide el token con un GET y devuelve la cookie de sesión que se emitió junto con él.
+func issue(t *testing.T, h http.Handler, token *string) *http.Cookie {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != session.CookieName || *token == "" {
+		t.Fatalf("GET no emitió sesión y token: %v, %q", cookies, *token)
+	}
+	return cookies[0]
+}
+
+func TestTokenBoundToSession(t *testing.T) {
+	p := New(testSecret, session.NewManager(testSecret))
+	var token string
+	h := p.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			token = Token(r)
+		}
+	}), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	mine := issue(t, h, &token)
+	myToken := token
+	other := issue(t, h, &token)
+
+	post := func(c *http.Cookie, tok string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{FieldName: {tok}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if c != nil {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := post(mine, myToken); code != http.StatusOK {
+		t.Errorf("token de la propia sesión = %d, quiero 200", code)
+	}
+	if code := post(other, myToken); code != http.StatusForbidden {
+		t.Errorf("token de otra sesión = %d, quiero 403", code)
+	}
+	if code := post(nil, myToken); code != http.StatusForbidden {
+		t.Errorf("sin sesión = %d, quiero 403", code)
+	}
+}
@@ -4,24 +4,33 @@ package db // Paquete 'db' → se usa para agrupar funciones relacionadas a la b
 
 import (
 	"context" // Manejo de cancelación y timeouts para llamadas externas (como conexiones de red)
+	"fmt"     // fmt.Errorf: error de CheckPrimary
+
 	// Driver oficial de MongoDB para Go
-	"go.mongodb.org/mongo-driver/mongo"         // Contiene los tipos Client, Collection, Cursor, etc.
-	"go.mongodb.org/mongo-driver/mongo/options" // Permite construir estructuras de configuración (ApplyURI, SetAuth, etc.)
+	"go.mongodb.org/mongo-driver/bson"           // Documentos BSON (comando "hello")
+	"go.mongodb.org/mongo-driver/event"          // event.CommandMonitor: observar los comandos (métricas)
+	"go.mongodb.org/mongo-driver/mongo"          // Contiene los tipos Client, Collection, Cursor, etc.
+	"go.mongodb.org/mongo-driver/mongo/options"  // Permite construir estructuras de configuración (ApplyURI, SetAuth, etc.)
+	"go.mongodb.org/mongo-driver/mongo/readpref" // A qué miembro del replica set preguntarle (primario / más cercano)
 )
 
 // Connect establece una conexión con MongoDB y devuelve un *mongo.Client listo para usar.
 // Parámetros:
 //
-//	ctx  → contexto (controla timeout o cancelación).
-//	uri  → cadena de conexión completa (ej: "mongodb://localhost:27017").
+//	ctx     → contexto (controla timeout o cancelación).
+//	uri     → cadena de conexión completa (ej: "mongodb://localhost:27017").
+//	monitor → observa cada comando enviado (métricas); nil = sin monitor.
 //
 // Devuelve:
 //   - *mongo.Client  → puntero al cliente conectado (es el objeto principal para operar con Mongo).
 //   - error          → error si algo falla al conectar o al hacer ping.
-func Connect(ctx context.Context, uri string) (*mongo.Client, error) {
+func Connect(ctx context.Context, uri string, monitor *event.CommandMonitor) (*mongo.Client, error) {
 	// options.Client() crea una estructura vacía de configuración para el cliente MongoDB.
 	// ApplyURI(uri) "inyecta" la cadena de conexión (con host, puerto, credenciales, etc.)
 	opts := options.Client().ApplyURI(uri)
+	if monitor != nil {
+		opts.SetMonitor(monitor) // el driver le avisa el inicio y el fin de cada comando
+	}
 
 	// mongo.Connect crea la conexión a MongoDB usando el contexto (ctx) y las opciones (opts).
 	// No es un simple "abrir socket": internamente inicializa conexiones en un pool.
@@ -42,3 +51,26 @@ func Connect(ctx context.Context, uri string) (*mongo.Client, error) {
 	// Si todo salió bien, devolvemos el cliente conectado.
 	return client, nil
 }
+
+// Ping verifica que el cliente llegue a algún miembro del replica set (el más cercano).
+func Ping(ctx context.Context, client *mongo.Client) error {
+	return client.Ping(ctx, readpref.Nearest())
+}
+
+// CheckPrimary verifica que haya un primario alcanzable y que acepte escrituras
+// (sin primario no se pueden crear pedidos: las transacciones necesitan escribir en él).
+func CheckPrimary(ctx context.Context, client *mongo.Client) error {
+	var hello struct {
+		IsWritablePrimary bool   `bson:"isWritablePrimary"`
+		SetName           string `bson:"setName"`
+	}
+	// El comando "hello" con read preference primary sólo lo puede contestar el primario
+	opts := options.RunCmd().SetReadPreference(readpref.Primary())
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}, opts).Decode(&hello); err != nil {
+		return err
+	}
+	if !hello.IsWritablePrimary {
+		return fmt.Errorf("el nodo %q no es primario", hello.SetName)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// cancel.go — handler para que el comprador cancele su pedido (POST /orders/:id/cancel)
+
+package handlers
+
+import (
+	"context"  // timeout de la transacción
+	"errors"   // errors.Is: distinguir errores del store
+	"log/slog" // slog: errores con el id de la request
+	"net/http" // servidor HTTP estándar
+	"strings"  // strings.TrimSpace: limpiar el motivo
+	"time"     // duración del timeout
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access" // verificación del token del comprador
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"  // repositorio de pedidos
+	"go.mongodb.org/mongo-driver/bson/primitive"                    // ObjectID de Mongo
+)
+
+// maxCancelReason: largo máximo (en caracteres) del motivo de cancelación que guardamos.
+const maxCancelReason = 300
+
+// defaultCancelReason: motivo que se guarda si el comprador no escribió ninguno.
+const defaultCancelReason = "cancelado por el comprador"
+
+// NewCancel construye el handler de POST /orders/<id>/cancel?token=...
+// Sólo se puede cancelar mientras el pedido está en "nuevo"; el pedido pasa a "cancellations"
+// y /status/ lo muestra como "cancelado".
+func NewCancel(orders store.OrderStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oid, err := primitive.ObjectIDFromHex(r.PathValue("id")) // {id} del patrón POST /orders/{id}/cancel
+		if err != nil {
+			http.Error(w, "ID de pedido inválido", http.StatusBadRequest)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "form inválido", http.StatusBadRequest)
+			return
+		}
+		token := r.URL.Query().Get("token")
+
+		// Motivo: opcional, recortado al largo máximo (en runas, para no partir caracteres UTF-8)
+		reason := strings.TrimSpace(r.PostFormValue("reason"))
+		if rs := []rune(reason); len(rs) > maxCancelReason {
+			reason = string(rs[:maxCancelReason])
+		}
+		if reason == "" {
+			reason = defaultCancelReason
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		// Verificamos el token antes de tocar nada (sin token → 404, igual que /status/)
+		order, err := orders.FindByID(ctx, oid)
+		if err == nil && !access.Match(order.AccessTokenHash, token) {
+			err = store.ErrNotFound
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "pedido no encontrado", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// Cancel es atómico y compare-and-swap sobre status = "nuevo"
+		_, err = orders.Cancel(ctx, oid, reason)
+		switch {
+		case errors.Is(err, store.ErrNotEditable):
+			http.Error(w, "tu pedido ya se está preparando y no se puede cancelar", http.StatusConflict)
+			return
+		case errors.Is(err, store.ErrNotFound):
+			http.Error(w, "pedido no encontrado", http.StatusNotFound)
+			return
+		case err != nil:
+			slog.ErrorContext(r.Context(), "error al cancelar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "no se pudo cancelar el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// PRG: volvemos al estado del pedido, que ahora muestra "cancelado"
+		http.Redirect(w, r, statusURL(oid, token), http.StatusSeeOther)
+	}
+}
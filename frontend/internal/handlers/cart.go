@@ -0,0 +1,250 @@
+// cart.go — carrito de compras por sesión
+// GET /cart muestra el carrito con el form del comprador (checkout desde el carrito);
+// POST /cart/add, /cart/update y /cart/remove cambian las cantidades y vuelven a /cart (PRG).
+// El carrito sólo guarda cantidades: nombres, precios y total salen del catálogo con
+// readLineItems, igual que en el checkout del form de la home.
+
+package handlers
+
+import (
+	"context"       // timeout de las consultas
+	"html/template" // *template.Template
+	"log/slog"      // slog: errores con el id de la request
+	"net/http"      // handlers HTTP
+	"net/url"       // url.Values: el carrito como si fuera el form de la home
+	"sort"          // filas del carrito en orden fijo
+	"strconv"       // strconv.Itoa / Atoi: cantidades
+	"strings"       // strings.TrimSpace
+	"time"          // timeouts
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"     // token CSRF de los forms
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"   // Cart
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session"  // cookie firmada con el id de sesión
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"    // repositorios de carritos y productos
+	"github.com/gastonduartem/Challenge-1/frontend/internal/validate" // límites de cantidad, sectores
+	"go.mongodb.org/mongo-driver/bson/primitive"                      // ObjectID
+)
+
+// cartLine: una fila del carrito.
+type cartLine struct {
+	ProductID string // ID hex (para los forms de actualizar/quitar)
+	Name      string // nombre del catálogo (vacío si el producto ya no existe)
+	Price     int    // precio actual
+	Qty       int
+	Max       int // tope del input (disponible, sin pasar de validate.MaxQty)
+	Subtotal  int
+	Error     string // problema de la línea (producto inactivo, sin stock, ...)
+}
+
+// cartView: datos de cart.tmpl (carrito + form del comprador para el checkout).
+type cartView struct {
+	Lines []cartLine
+	Total int
+
+	DefaultName    string
+	DefaultEmail   string
+	DefaultAddress string
+	DefaultSector  string
+	Sectors        []string
+
+	FieldErrors validate.Errors // errores por campo del comprador
+	Errors      []string        // errores generales (arriba)
+	CSRF        string          // token CSRF de los forms (lo completa renderCart)
+}
+
+// renderCart renderiza cart.tmpl con el código de estado indicado.
+func renderCart(w http.ResponseWriter, r *http.Request, tmpl *template.Template, status int, v cartView) {
+	v.CSRF = csrf.Token(r)
+	render(w, r, tmpl, "cart.tmpl", status, v)
+}
+
+// cartForm convierte el carrito en campos qty_<idHex>, los mismos del form de la home,
+// para reutilizar readLineItems (precios del servidor, validaciones por línea).
+func cartForm(c models.Cart) url.Values {
+	form := url.Values{}
+	for idHex, qty := range c.Items {
+		form.Set("qty_"+idHex, strconv.Itoa(qty))
+	}
+	return form
+}
+
+// cartLines arma las filas a partir de las líneas leídas; lineErrs (ej: faltantes de stock)
+// tiene prioridad sobre los errores de lectura.
+func cartLines(lines lineItems, lineErrs map[string]string) []cartLine {
+	items := map[string]models.Item{}
+	for _, it := range lines.Items {
+		items[it.ProductID.Hex()] = it
+	}
+	ids := make([]string, 0, len(lines.Qtys))
+	for idHex := range lines.Qtys {
+		ids = append(ids, idHex)
+	}
+	sort.Strings(ids)
+
+	out := make([]cartLine, 0, len(ids))
+	for _, idHex := range ids {
+		it := items[idHex]
+		msg := lineErrs[idHex]
+		if msg == "" {
+			msg = lines.Errors[idHex]
+		}
+		out = append(out, cartLine{
+			ProductID: idHex,
+			Name:      it.Name,
+			Price:     it.UnitPrice,
+			Qty:       lines.Qtys[idHex],
+			Max:       lines.Limits[idHex],
+			Subtotal:  it.Subtotal,
+			Error:     msg,
+		})
+	}
+	return out
+}
+
+// NewCart arma el handler de GET /cart.
+// - carts / products: repositorios del carrito y del catálogo
+// - sessions: lee la cookie de sesión (sin crearla: mirar el carrito vacío no deja cookies)
+// - tmpl: conjunto de templates (usa "cart.tmpl")
+func NewCart(carts store.CartStore, products store.ProductStore, sessions *session.Manager, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		view := cartView{Sectors: validate.IglooSectors}
+		if sid, ok := sessions.Peek(r); ok {
+			cart, err := carts.Get(ctx, sid)
+			if err != nil {
+				slog.ErrorContext(ctx, "error al leer el carrito", "err", err)
+				http.Error(w, "error al obtener el carrito", http.StatusInternalServerError)
+				return
+			}
+			lines, err := readLineItems(ctx, products, cartForm(cart), nil)
+			if err != nil {
+				slog.ErrorContext(ctx, "no se pudieron leer los productos del carrito", "err", err)
+				http.Error(w, "error al obtener productos", http.StatusInternalServerError)
+				return
+			}
+			view.Lines, view.Total = cartLines(lines, nil), lines.Total
+			view.Errors = lines.Problems
+		}
+		renderCart(w, r, tmpl, http.StatusOK, view)
+	}
+}
+
+// cartProductID lee product_id del form (POST ya parseado).
+func cartProductID(r *http.Request) (primitive.ObjectID, bool) {
+	oid, err := primitive.ObjectIDFromHex(strings.TrimSpace(r.PostFormValue("product_id")))
+	return oid, err == nil
+}
+
+// NewCartAdd arma el handler de POST /cart/add (product_id + qty opcional, por defecto 1).
+// Si la suma con lo que ya había pasa de validate.MaxQty se deja en el máximo; si pasa de lo
+// disponible queda así y el carrito lo marca (el checkout la rechaza hasta que se corrija).
+func NewCartAdd(carts store.CartStore, products store.ProductStore, sessions *session.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "form inválido", http.StatusBadRequest)
+			return
+		}
+		oid, ok := cartProductID(r)
+		if !ok {
+			http.Error(w, "producto inválido", http.StatusBadRequest)
+			return
+		}
+
+		raw := strings.TrimSpace(r.PostFormValue("qty"))
+		qty := 1
+		if raw != "" && raw != "0" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || validate.Quantity(n) != nil {
+				http.Error(w, "cantidad inválida", http.StatusBadRequest)
+				return
+			}
+			qty = n
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		// Sólo se agregan productos que están a la venta y con unidades disponibles
+		p, err := products.FindByID(ctx, oid)
+		if err != nil || !p.Active {
+			http.Error(w, "producto no disponible", http.StatusNotFound)
+			return
+		}
+		if p.SoldOut() {
+			http.Error(w, "producto agotado", http.StatusConflict)
+			return
+		}
+
+		sid, err := sessions.ID(w, r) // crea la sesión (Set-Cookie) si es la primera vez
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudo crear la sesión", "err", err)
+			http.Error(w, "no se pudo actualizar el carrito", http.StatusInternalServerError)
+			return
+		}
+		cart, err := carts.Get(ctx, sid)
+		if err == nil {
+			err = carts.SetQty(ctx, sid, oid, min(cart.Items[oid.Hex()]+qty, validate.MaxQty))
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error al agregar al carrito", "product_id", oid.Hex(), "err", err)
+			http.Error(w, "no se pudo actualizar el carrito", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+	}
+}
+
+// NewCartUpdate arma el handler de POST /cart/update (product_id + qty; 0 quita la línea).
+func NewCartUpdate(carts store.CartStore, sessions *session.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "form inválido", http.StatusBadRequest)
+			return
+		}
+		oid, ok := cartProductID(r)
+		if !ok {
+			http.Error(w, "producto inválido", http.StatusBadRequest)
+			return
+		}
+		qty, err := strconv.Atoi(strings.TrimSpace(r.PostFormValue("qty")))
+		if err != nil || (qty != 0 && validate.Quantity(qty) != nil) {
+			http.Error(w, "cantidad inválida", http.StatusBadRequest)
+			return
+		}
+		updateCartLine(w, r, carts, sessions, oid, qty)
+	}
+}
+
+// NewCartRemove arma el handler de POST /cart/remove (product_id).
+func NewCartRemove(carts store.CartStore, sessions *session.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "form inválido", http.StatusBadRequest)
+			return
+		}
+		oid, ok := cartProductID(r)
+		if !ok {
+			http.Error(w, "producto inválido", http.StatusBadRequest)
+			return
+		}
+		updateCartLine(w, r, carts, sessions, oid, 0)
+	}
+}
+
+// updateCartLine fija la cantidad de una línea del carrito de la sesión y vuelve a /cart.
+// Sin sesión no hay carrito que cambiar: se vuelve directo.
+func updateCartLine(w http.ResponseWriter, r *http.Request, carts store.CartStore, sessions *session.Manager, oid primitive.ObjectID, qty int) {
+	sid, ok := sessions.Peek(r)
+	if ok {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		if err := carts.SetQty(ctx, sid, oid, qty); err != nil {
+			slog.ErrorContext(ctx, "error al actualizar el carrito", "product_id", oid.Hex(), "err", err)
+			http.Error(w, "no se pudo actualizar el carrito", http.StatusInternalServerError)
+			return
+		}
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
@@ -0,0 +1,177 @@
+// catalog.go — filtros, orden y paginación del catálogo (query string de GET / y GET /category/:slug)
+// Todo se resuelve en el servidor: el form de filtros es un GET común y los links
+// de paginación repiten los mismos parámetros, así que no hace falta JavaScript.
+
+package handlers
+
+import (
+	"math"    // math.MaxInt: tope de ?page=
+	"net/url" // url.Values: leer y armar el query string
+	"strconv" // strconv.Atoi / Itoa: números del query string
+	"strings" // strings.TrimSpace
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models" // Categories
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"  // CatalogQuery / CatalogSort
+)
+
+// Tamaños de página que acepta ?page_size= (cualquier otro valor usa el default).
+var pageSizes = []int{12, 24, 48}
+
+const (
+	defaultPageSize = 12
+	maxPageSize     = 48 // el mayor de pageSizes
+)
+
+// maxPage: tope de ?page=, así (page-1)*pageSize nunca desborda un int. Una página más allá
+// del final se muestra como la última (serveCatalog).
+const maxPage = math.MaxInt / maxPageSize
+
+// maxSearchLen: largo máximo del texto de búsqueda (lo demás se descarta).
+const maxSearchLen = 100
+
+// sortOptions: órdenes del <select> del form de filtros.
+var sortOptions = []sortOption{
+	{store.SortRelevance, "Relevancia"},
+	{store.SortName, "Nombre"},
+	{store.SortPriceAsc, "Precio: menor a mayor"},
+	{store.SortPriceDesc, "Precio: mayor a menor"},
+	{store.SortNewest, "Más nuevos"},
+}
+
+type sortOption struct {
+	Value store.CatalogSort
+	Label string
+}
+
+// pageLink: un link del paginador.
+type pageLink struct {
+	Num     int
+	URL     string
+	Current bool
+}
+
+// catalogView: estado del form de filtros y del paginador en home.tmpl.
+type catalogView struct {
+	Query      store.CatalogQuery // filtros aplicados (para volver a llenar el form)
+	Action     string             // path del listado ("/" o "/category/<slug>"): action del form de filtros
+	Category   models.Category    // categoría elegida (Slug vacío = todas)
+	Categories []models.Category  // navegación por categoría
+	Total      int                // productos que cumplen los filtros
+	Pages      int                // cantidad de páginas
+	PrevURL    string             // vacío en la primera página
+	NextURL    string             // vacío en la última
+	Links      []pageLink         // links numerados
+	SortOpts   []sortOption
+	PageSizes  []int
+}
+
+// Filtered indica si hay algún filtro aplicado (para mostrar "limpiar filtros").
+func (c catalogView) Filtered() bool {
+	q := c.Query
+	return q.Text != "" || q.MinPrice > 0 || q.MaxPrice > 0 || q.InStock
+}
+
+// positiveInt lee un entero > 0 del query string; cualquier otra cosa es 0 (sin valor).
+func positiveInt(v url.Values, key string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(v.Get(key)))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseCatalogQuery lee los filtros de ?q=&min_price=&max_price=&in_stock=1&sort=&page=&page_size=.
+// Es un GET de navegación: los valores inválidos no dan error, se ignoran (vuelven al default).
+func parseCatalogQuery(v url.Values) store.CatalogQuery {
+	q := store.CatalogQuery{
+		Text:     strings.TrimSpace(v.Get("q")),
+		MinPrice: positiveInt(v, "min_price"),
+		MaxPrice: positiveInt(v, "max_price"),
+		InStock:  v.Get("in_stock") == "1",
+		Sort:     store.CatalogSort(v.Get("sort")),
+		Page:     positiveInt(v, "page"),
+		PageSize: defaultPageSize,
+	}
+	if r := []rune(q.Text); len(r) > maxSearchLen {
+		q.Text = string(r[:maxSearchLen])
+	}
+	if q.MaxPrice > 0 && q.MinPrice > q.MaxPrice {
+		q.MinPrice, q.MaxPrice = q.MaxPrice, q.MinPrice // rango al revés: lo damos vuelta
+	}
+	if !q.Sort.Valid() {
+		q.Sort = store.SortRelevance
+	}
+	q.Page = min(max(q.Page, 1), maxPage)
+	size := positiveInt(v, "page_size")
+	for _, s := range pageSizes {
+		if size == s {
+			q.PageSize = s
+		}
+	}
+	return q
+}
+
+// catalogPath: "/" o "/category/<slug>" según la categoría de q.
+func catalogPath(q store.CatalogQuery) string {
+	if q.Category != "" {
+		return "/category/" + q.Category
+	}
+	return "/"
+}
+
+// catalogURL arma el link al listado de q (home o categoría) en la página page (omite los defaults).
+func catalogURL(q store.CatalogQuery, page int) string {
+	v := url.Values{}
+	if q.Text != "" {
+		v.Set("q", q.Text)
+	}
+	if q.MinPrice > 0 {
+		v.Set("min_price", strconv.Itoa(q.MinPrice))
+	}
+	if q.MaxPrice > 0 {
+		v.Set("max_price", strconv.Itoa(q.MaxPrice))
+	}
+	if q.InStock {
+		v.Set("in_stock", "1")
+	}
+	if q.Sort != store.SortRelevance {
+		v.Set("sort", string(q.Sort))
+	}
+	if q.PageSize != defaultPageSize {
+		v.Set("page_size", strconv.Itoa(q.PageSize))
+	}
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	if len(v) == 0 {
+		return catalogPath(q)
+	}
+	return catalogPath(q) + "?" + v.Encode()
+}
+
+// newCatalogView arma el paginador para q con total resultados.
+func newCatalogView(q store.CatalogQuery, total int) catalogView {
+	pages := (total + q.PageSize - 1) / q.PageSize
+	c := catalogView{
+		Query:      q,
+		Action:     catalogPath(q),
+		Categories: models.Categories,
+		Total:      total,
+		Pages:      pages,
+		SortOpts:   sortOptions,
+		PageSizes:  pageSizes,
+	}
+	c.Category, _ = models.CategoryBySlug(q.Category)
+	if q.Page > 1 {
+		c.PrevURL = catalogURL(q, q.Page-1)
+	}
+	if q.Page < pages {
+		c.NextURL = catalogURL(q, q.Page+1)
+	}
+	if pages > 1 {
+		for n := 1; n <= pages; n++ {
+			c.Links = append(c.Links, pageLink{Num: n, URL: catalogURL(q, n), Current: n == q.Page})
+		}
+	}
+	return c
+}
@@ -4,46 +4,58 @@ package handlers // Paquete donde viven los handlers (controladores HTTP)
 
 // ====== IMPORTS ======
 import (
-	"context"  // context.Context: transporta deadlines, cancelaciones y metadatos entre llamadas
-	"net/http" // net/http: servidor y utilidades HTTP estándar en Go
-	"strconv"  // strconv: convertir strings a números (Atoi)
-	"strings"  // strings: utilidades para manipular strings (TrimSpace, HasPrefix)
-	"time"     // time: trabajar con tiempos, deadlines y timeouts
-
+	"context"       // context.Context: transporta deadlines, cancelaciones y metadatos entre llamadas
+	"errors"        // errors.As: detectar *store.StockError
+	"html/template" // html/template: para volver a renderizar la home con los errores
+	"log/slog"      // slog: errores con el id de la request
+	"net/http"      // net/http: servidor y utilidades HTTP estándar en Go
+	"net/url"       // url.Values: líneas del carrito con el mismo formato que el form
+	"strings"       // strings: utilidades para manipular strings (TrimSpace, HasPrefix)
+	"time"          // time: trabajar con tiempos, deadlines y timeouts
+
+	// metrics: resultados del checkout y valor de los pedidos (Prometheus)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/metrics"
 	// models: tus tipos de dominio (Product, Item, Order) definidos en internal/models
 	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
-
-	"go.mongodb.org/mongo-driver/bson"           // bson: representación binaria JSON para Mongo (documentos y filtros)
-	"go.mongodb.org/mongo-driver/bson/primitive" // primitive: tipos especiales de Mongo (ObjectID, Decimal128, etc.)
-	"go.mongodb.org/mongo-driver/mongo"          // mongo: cliente/colecciones/métodos para operar con MongoDB
+	// store: repositorios de productos y pedidos (Mongo o memoria)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"
+	// access: token secreto para que sólo el comprador vea/edite su pedido
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access"
+	// session: id de sesión firmado (carrito)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session"
+	// validate: reglas de los campos del form (email, largos, cantidades, sector)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/validate"
 )
 
 // NewCheckout devuelve un http.HandlerFunc (función que maneja una ruta HTTP)
-// Recibe dos *mongo.Collection (punteros a colecciones):
-//   - colProducts: colección "products" (para leer nombre/precio confiables)
-//   - colOrders:   colección "orders" (para insertar el pedido nuevo)
-func NewCheckout(colProducts, colOrders *mongo.Collection) http.HandlerFunc {
+// Recibe:
+//   - products: repositorio de productos (para leer nombre/precio confiables)
+//   - orders:   repositorio de pedidos (reserva stock e inserta el pedido en una transacción)
+//   - carts / sessions: checkout desde el carrito (form de /cart con source=cart)
+//   - uploadsBase / tmpl: para volver a renderizar la home (o el carrito) si el pedido se rechaza
+//
+// Los ítems salen de los campos qty_<id> del form de la home o, si source=cart, del carrito
+// de la sesión; en ese caso el carrito se vacía cuando el pedido se crea.
+func NewCheckout(products store.ProductStore, orders store.OrderStore, carts store.CartStore, sessions *session.Manager, uploadsBase string, tmpl *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) { // w: writer de la respuesta; r: request entrante
-		if r.Method != http.MethodPost { // Validamos método: sólo aceptamos POST (en SSR, viene de un <form>)
-			http.Error(w, "solo se acepta POST", http.StatusMethodNotAllowed) // 405 si no es POST
-			return
-		}
 		if err := r.ParseForm(); err != nil { // ParseForm: parsea body application/x-www-form-urlencoded
+			metrics.Checkout(metrics.CheckoutValidation)
 			http.Error(w, "form inválido", http.StatusBadRequest) // 400 si no se pudo parsear
 			return
 		}
 
 		// Leemos campos básicos del comprador; TrimSpace elimina espacios al inicio/fin
-		buyer := strings.TrimSpace(r.FormValue("buyer_name")) // nombre del comprador
-		address := strings.TrimSpace(r.FormValue("address"))  // dirección/iglú
-		email := strings.TrimSpace(r.FormValue("email"))      // email
-		if buyer == "" || address == "" || email == "" {      // validación mínima
-			http.Error(w, "completá nombre, dirección y email", http.StatusBadRequest)
-			return
-		}
-
-		var items []models.Item // Slice dinámico de Items (los ítems del pedido)
-		total := 0              // Total en enteros (centavos o unidades, según tu decisión)
+		buyer := strings.TrimSpace(r.FormValue("buyer_name"))    // nombre del comprador
+		address := strings.TrimSpace(r.FormValue("address"))     // dirección/iglú
+		email := strings.TrimSpace(r.FormValue("email"))         // email
+		sector := strings.TrimSpace(r.FormValue("igloo_sector")) // sector del iglú (opcional)
+
+		// Validación por campo: cada mensaje se muestra debajo de su input
+		fieldErrs := validate.Errors{}
+		fieldErrs.Check("buyer_name", validate.Text("El nombre", buyer, validate.MaxNameLen))
+		fieldErrs.Check("address", validate.Text("La dirección", address, validate.MaxAddressLen))
+		fieldErrs.Check("email", validate.Email(email))
+		fieldErrs.Check("igloo_sector", validate.IglooSector(sector))
 
 		// context.WithTimeout crea un context.Context hijo con deadline (timeout de 5s)
 		// - r.Context(): contexto que viaja con la request (se cancela si el cliente se desconecta)
@@ -51,75 +63,143 @@ func NewCheckout(colProducts, colOrders *mongo.Collection) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		// Recorremos todos los pares key->values del form para detectar campos qty_<productID>
-		for key, vals := range r.Form {
-			if !strings.HasPrefix(key, "qty_") || len(vals) == 0 { // sólo procesamos campos que empiezan con "qty_"
-				continue
-			}
-			qty, _ := strconv.Atoi(vals[0]) // convertimos el primer valor a int (si falla, qty queda 0)
-			if qty <= 0 {                   // ignoramos cantidades no positivas
-				continue
-			}
-
-			// key = "qty_<idHex>" → extraemos la parte del ObjectID en hex
-			idHex := strings.TrimPrefix(key, "qty_")
-			// primitive.ObjectIDFromHex convierte un string hex de 24 chars al tipo ObjectID de Mongo
-			oid, err := primitive.ObjectIDFromHex(idHex)
-			if err != nil { // si el id no es un ObjectID válido, ignoramos este campo
-				continue
+		// Origen de las líneas: el form de la home, o el carrito de la sesión (mismos campos qty_<id>)
+		lineForm := r.Form
+		fromCart := r.FormValue("source") == "cart"
+		sid, hasSession := sessions.Peek(r)
+		if fromCart {
+			lineForm = url.Values{}
+			if hasSession {
+				cart, err := carts.Get(ctx, sid)
+				if err != nil {
+					slog.ErrorContext(ctx, "error al leer el carrito", "err", err)
+					metrics.Checkout(metrics.CheckoutError)
+					http.Error(w, "error al obtener el carrito", http.StatusInternalServerError)
+					return
+				}
+				lineForm = cartForm(cart)
 			}
+		}
 
-			// Buscamos el producto en Mongo para traer nombre y precio "confiables" (server-side)
-			// Definimos un struct anónimo con sólo los campos que vamos a leer (name y price)
-			var p struct {
-				Name  string `bson:"name"`  // tag bson: nombre exacto del campo en el documento
-				Price int    `bson:"price"` // precio entero
+		// Armamos los ítems a partir de los campos qty_<productID> (precios leídos del servidor)
+		// Cualquier línea que no se pueda armar rechaza el pedido entero (nunca se descarta en silencio)
+		lines, err := readLineItems(ctx, products, lineForm, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudieron leer los productos", "err", err)
+			metrics.Checkout(metrics.CheckoutError)
+			http.Error(w, "error al obtener productos", http.StatusInternalServerError)
+			return
+		}
+		items, total, qtys := lines.Items, lines.Total, lines.Qtys
+
+		// rerender vuelve a mostrar la home (o el carrito) con lo que cargó el comprador y los errores
+		rerender := func(status int, lineErrs map[string]string, msgs ...string) {
+			if fromCart {
+				renderCart(w, r, tmpl, status, cartView{
+					Lines:          cartLines(lines, lineErrs),
+					Total:          total,
+					DefaultName:    buyer,
+					DefaultEmail:   email,
+					DefaultAddress: address,
+					DefaultSector:  sector,
+					Sectors:        validate.IglooSectors,
+					FieldErrors:    fieldErrs,
+					Errors:         msgs,
+				})
+				return
 			}
-			// colProducts.FindOne ejecuta un find con filtro; Decode rellena 'p'
-			// bson.M es un alias de map[string]interface{} para armar documentos/filtros BSON
-			// &: puntero
-			if err := colProducts.FindOne(ctx, bson.M{"_id": oid}).Decode(&p); err != nil {
-				continue // si no existe el producto (o error de DB), salteamos este ítem
+			list, err := products.ListActive(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "no se pudo leer el catálogo", "err", err)
+				http.Error(w, "error al obtener productos", http.StatusInternalServerError)
+				return
 			}
-
-			sub := p.Price * qty // subtotal por ítem = precio * cantidad
-			total += sub         // acumulamos al total del pedido
-
-			// Armamos el Item con snapshot + ProductID (lo usa el admin para descontar stock)
-			items = append(items, models.Item{
-				ProductID: oid,     // ObjectID del producto (para auditoría/stock)
-				Name:      p.Name,  // snapshot de nombre (evita lookup futuro)
-				Qty:       qty,     // cantidad solicitada
-				UnitPrice: p.Price, // snapshot de precio
-				Subtotal:  sub,     // subtotal calculado
+			renderHome(w, r, tmpl, status, homeView{
+				Products:       list,
+				UploadsBase:    uploadsBase,
+				DefaultName:    buyer,
+				DefaultEmail:   email,
+				DefaultAddress: address,
+				DefaultSector:  sector,
+				Sectors:        validate.IglooSectors,
+				Qtys:           qtys,
+				LineErrors:     lineErrs, // ID hex → mensaje, en cada tarjeta de la home
+				FieldErrors:    fieldErrs,
+				Errors:         msgs,
 			})
 		}
 
-		if len(items) == 0 { // si no se eligió ningún producto válido
-			http.Error(w, "elegí al menos un producto", http.StatusBadRequest)
+		switch {
+		case lines.Errors.Any():
+			slog.DebugContext(ctx, "checkout rechazado por líneas inválidas", "problems", lines.Problems)
+			metrics.Checkout(metrics.CheckoutValidation)
+			rerender(http.StatusBadRequest, lines.Errors, append([]string{"No pudimos tomar tu pedido por estos productos:"}, lines.Problems...)...)
+			return
+		case len(items) == 0: // si no se eligió ningún producto válido
+			metrics.Checkout(metrics.CheckoutValidation)
+			rerender(http.StatusBadRequest, nil, "Elegí al menos un producto.")
+			return
+		case fieldErrs.Any():
+			metrics.Checkout(metrics.CheckoutValidation)
+			rerender(http.StatusBadRequest, nil, "Revisá los datos marcados.")
 			return
 		}
 
-		// Documento BSON que insertaremos en la colección "orders"
-		// bson.M: mapa estilo JSON → Mongo guardará tipos según los valores proporcionados
-		order := bson.M{
-			"items":        items,      // array de subdocs (cada uno con Name/Qty/UnitPrice/Subtotal/ProductID)
-			"total":        total,      // total del pedido
-			"buyer_name":   buyer,      // nombre comprador
-			"address":      address,    // dirección/iglú
-			"igloo_sector": "",         // sector opcional (aquí vacío)
-			"email":        email,      // correo
-			"status":       "nuevo",    // estado inicial
-			"created_at":   time.Now(), // timestamp de creación (tipo time.Time → BSON Date)
+		// Token de acceso del comprador: se lo mostramos una sola vez y en la DB guardamos el hash
+		token, tokenHash, err := access.New()
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudo generar el token de acceso", "err", err)
+			metrics.Checkout(metrics.CheckoutError)
+			http.Error(w, "no se pudo crear el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// Pedido que insertaremos en la colección "orders"
+		order := models.Order{
+			Items:       items,              // ítems (cada uno con Name/Qty/UnitPrice/Subtotal/ProductID)
+			Total:       total,              // total del pedido
+			BuyerName:   buyer,              // nombre comprador
+			Address:     address,            // dirección/iglú
+			Email:       email,              // correo
+			IglooSector: sector,             // sector del iglú (opcional)
+			Status:      models.StatusNuevo, // estado inicial
+
+			AccessTokenHash: tokenHash, // sólo el hash; el token viaja en los links del comprador
+		}
+		// Chequeo de integridad contra las reglas del esquema (Order.js) antes de tocar la DB
+		if err := order.Validate(); err != nil {
+			metrics.Checkout(metrics.CheckoutValidation)
+			http.Error(w, "pedido inválido: "+err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		// InsertOne inserta un documento en la colección; devuelve InsertOneResult o error
-		if _, err := colOrders.InsertOne(ctx, order); err != nil {
+		// Create reserva el stock de cada línea e inserta el pedido de forma atómica (transacción)
+		err = orders.Create(ctx, &order)
+
+		var stockErr *store.StockError
+		if errors.As(err, &stockErr) {
+			// Rechazamos el pedido entero y mostramos la home otra vez con el error en cada producto
+			metrics.Checkout(metrics.CheckoutStock)
+			rerender(http.StatusConflict, shortageMessages(stockErr), "No pudimos tomar tu pedido: revisá las cantidades marcadas.")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "no se pudo crear el pedido", "err", err)
+			metrics.Checkout(metrics.CheckoutError)
 			http.Error(w, "no se pudo crear el pedido", http.StatusInternalServerError) // 500 si falla la DB
 			return
 		}
 
-		// Redirigimos a /orders (listado público de pedidos con estado) — 303 See Other (PRG)
-		http.Redirect(w, r, "/orders", http.StatusSeeOther)
+		// El pedido ya tiene las unidades reservadas: vaciamos el carrito (si falla, sólo queda el carrito lleno)
+		if fromCart && hasSession {
+			if err := carts.Clear(ctx, sid); err != nil {
+				slog.WarnContext(ctx, "no se pudo vaciar el carrito", "order_id", order.ID.Hex(), "err", err)
+			}
+		}
+
+		metrics.OrderPlaced(order.Total) // checkout exitoso + valor del pedido
+
+		// Redirigimos al comprobante del pedido (link privado con el token) — 303 See Other (PRG)
+		http.Redirect(w, r, confirmationURL(order.ID, token), http.StatusSeeOther)
 	}
 }
@@ -0,0 +1,83 @@
+// confirmation.go — handler SSR del comprobante del pedido (GET /orders/:id/confirmation)
+// Es el destino del redirect 303 después del checkout (patrón PRG).
+
+package handlers
+
+import (
+	"context"       // timeout para la consulta a la DB
+	"errors"        // errors.Is: distinguir store.ErrNotFound
+	"html/template" // *template.Template
+	"log/slog"      // slog: errores con el id de la request
+	"net/http"      // servidor HTTP estándar
+	"time"          // duración del timeout
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access" // verificación del token del comprador
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models" // modelo de pedido
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"  // repositorio de pedidos
+	"go.mongodb.org/mongo-driver/bson/primitive"                    // ObjectID de Mongo
+)
+
+// ConfirmationView: datos que consume order_confirmation.tmpl.
+type ConfirmationView struct {
+	ShortID   string        // ID corto visual (#a1b2)
+	BuyerName string        // nombre del comprador
+	Address   string        // dirección/iglú
+	Email     string        // email del comprador
+	Items     []models.Item // ítems con precio y subtotal (snapshot del checkout)
+	Total     int           // total recalculado en el servidor a partir de los ítems
+	Token     string        // token de acceso (el comprador lo tiene que guardar)
+	StatusURL string        // link privado a /status/<id>
+	EditURL   string        // link privado a /orders/<id>/edit (vacío si el pedido ya no es editable)
+}
+
+// NewConfirmation construye el handler de GET /orders/<id>/confirmation?token=...
+// Igual que /status/, sin el token correcto respondemos 404.
+func NewConfirmation(orders store.OrderStore, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oid, err := primitive.ObjectIDFromHex(r.PathValue("id")) // {id} del patrón GET /orders/{id}/confirmation
+		if err != nil {
+			http.Error(w, "ID de pedido inválido", http.StatusBadRequest)
+			return
+		}
+		token := r.URL.Query().Get("token")
+
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		order, err := orders.FindByID(ctx, oid)
+		if err == nil && !access.Match(order.AccessTokenHash, token) {
+			err = store.ErrNotFound // token ausente o incorrecto → igual que si no existiera
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "pedido no encontrado", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// El total que mostramos sale de los ítems (calculados en el checkout con precios de la DB),
+		// no de nada que haya mandado el navegador.
+		total := 0
+		for _, it := range order.Items {
+			total += it.Subtotal
+		}
+
+		view := ConfirmationView{
+			ShortID:   order.ShortID(),
+			BuyerName: order.BuyerName,
+			Address:   order.Address,
+			Email:     order.Email,
+			Items:     order.Items,
+			Total:     total,
+			Token:     token,
+			StatusURL: statusURL(order.ID, token),
+		}
+		if order.Editable() {
+			view.EditURL = editURL(order.ID, token)
+		}
+		render(w, r, tmpl, "order_confirmation.tmpl", http.StatusOK, view)
+	}
+}
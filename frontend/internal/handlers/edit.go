@@ -2,127 +2,276 @@ package handlers // paquete donde viven tus handlers HTTP
 
 import (
 	"context"       // manejar contexto y timeout
+	"errors"        // errors.Is / errors.As: distinguir errores del store
 	"html/template" // tipo *template.Template
+	"log/slog"      // slog: errores con el id de la request
 	"net/http"      // servidor y tipos HTTP
+	"strings"       // strings.TrimSpace: limpiar los campos del form
 	"time"          // timeout para operaciones con la DB
 
-	"go.mongodb.org/mongo-driver/bson"           // filtros BSON para Mongo
-	"go.mongodb.org/mongo-driver/bson/primitive" // ObjectID de Mongo
-	"go.mongodb.org/mongo-driver/mongo"          // tipo *mongo.Collection
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access"   // verificación del token del comprador
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"     // token CSRF del form
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"   // modelo de pedido
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"    // repositorios de pedidos y productos
+	"github.com/gastonduartem/Challenge-1/frontend/internal/validate" // reglas de los campos del form
+	"go.mongodb.org/mongo-driver/bson/primitive"                      // ObjectID de Mongo
 )
 
-// Item representa un item dentro de una orden (simplificado para el form)
-type Item struct {
-	ProductID primitive.ObjectID `bson:"product_id,omitempty"` // id del producto
-	Name      string             `bson:"name"`                 // nombre del producto
-	Qty       int                `bson:"qty"`                  // cantidad
-	UnitPrice int                `bson:"unit_price"`           // precio unitario
-	Subtotal  int                `bson:"subtotal"`             // subtotal
+// editLine: una fila del form de edición (un producto con la cantidad que tiene en el pedido).
+type editLine struct {
+	ProductID primitive.ObjectID // producto
+	Name      string             // nombre (del catálogo, o snapshot si ya no está activo)
+	Price     int                // precio actual del catálogo (el que se va a cobrar)
+	Qty       int                // cantidad en el pedido (0 = no está / se quita)
+	Available bool               // false si el producto ya no está en el catálogo
+	Max       int                // tope del input: lo disponible + lo que este pedido ya reservó
+	Error     string             // error de esta línea (ej: stock insuficiente)
 }
 
-// Order representa el documento en la colección "orders"
-type Order struct {
-	ID        primitive.ObjectID `bson:"_id"`        // id de la orden
-	BuyerName string             `bson:"buyer_name"` // nombre del cliente
-	Address   string             `bson:"address"`    // dirección
-	Email     string             `bson:"email"`      // email
-	Status    string             `bson:"status"`     // estado (nuevo, en_proceso, etc.)
-	Items     []Item             `bson:"items"`      // listado de items
-	Total     int                `bson:"total"`      // total del pedido
+// editView: datos de edit.tmpl → el pedido (campos promovidos: .ID, .BuyerName, ...) + el token,
+// que el form tiene que reenviar en el POST, + las filas de productos editables.
+type editView struct {
+	models.Order
+	Token   string
+	Lines   []editLine
+	Sectors []string // opciones del <select> de igloo_sector
+
+	FieldErrors validate.Errors // errores por campo (buyer_name, address, igloo_sector)
+	Errors      []string        // errores generales (arriba del form)
+	CSRF        string          // token CSRF del form
+}
+
+// editLines arma las filas del form: todos los productos activos con la cantidad que tiene
+// el pedido (qtys, por ID hex), más los ítems del pedido cuyo producto ya no está activo.
+func editLines(catalog []models.Product, order models.Order, qtys map[string]int, lineErrors map[string]string) []editLine {
+	lines := make([]editLine, 0, len(catalog))
+	seen := map[primitive.ObjectID]bool{}
+	owned := map[primitive.ObjectID]int{}
+	for _, it := range order.Items {
+		owned[it.ProductID] += it.Qty
+	}
+	for _, p := range catalog {
+		seen[p.ID] = true
+		lines = append(lines, editLine{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Price:     p.Price,
+			Qty:       qtys[p.ID.Hex()],
+			Available: true,
+			Max:       min(p.Available()+owned[p.ID], validate.MaxQty),
+			Error:     lineErrors[p.ID.Hex()],
+		})
+	}
+	for _, it := range order.Items {
+		if seen[it.ProductID] {
+			continue
+		}
+		lines = append(lines, editLine{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			Price:     it.UnitPrice,
+			Qty:       qtys[it.ProductID.Hex()],
+			Max:       owned[it.ProductID], // inactivo: se puede bajar o quitar, no aumentar
+			Error:     lineErrors[it.ProductID.Hex()],
+		})
+	}
+	return lines
+}
+
+// editTarget: lo que comparten el GET y el POST de /orders/{id}/edit.
+type editTarget struct {
+	order   models.Order     // pedido tal como está en la DB
+	token   string           // token del comprador (vuelve en el action del form y en los links)
+	catalog []models.Product // catálogo actual: productos que se pueden agregar y precios vigentes
+}
+
+// loadEditable busca el pedido de /orders/{id}/edit?token=..., verifica el token y que siga
+// en "nuevo", y trae el catálogo. Si algo falla ya respondió y devuelve false.
+func loadEditable(ctx context.Context, w http.ResponseWriter, r *http.Request, orders store.OrderStore, products store.ProductStore) (editTarget, bool) {
+	// {id} del patrón; si el formato no es válido, devolvemos error 400
+	objID, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "id inválido", http.StatusBadRequest)
+		return editTarget{}, false
+	}
+
+	// el token viaja en el query string tanto en el GET como en el action del form (POST)
+	token := r.URL.Query().Get("token")
+
+	order, err := orders.FindByID(ctx, objID)
+	if err == nil && !access.Match(order.AccessTokenHash, token) {
+		// token ausente o incorrecto: respondemos igual que si no existiera (no revelamos el pedido)
+		err = store.ErrNotFound
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "pedido no encontrado", http.StatusNotFound)
+		return editTarget{}, false
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", objID.Hex(), "err", err)
+		http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+		return editTarget{}, false
+	}
+
+	// Regla de negocio: solo se puede editar si el estado es "nuevo"
+	if !order.Editable() {
+		http.Error(w, "solo se pueden editar pedidos con estado 'nuevo'", http.StatusBadRequest)
+		return editTarget{}, false
+	}
+
+	catalog, err := products.ListActive(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "no se pudo leer el catálogo", "order_id", objID.Hex(), "err", err)
+		http.Error(w, "error al obtener productos", http.StatusInternalServerError)
+		return editTarget{}, false
+	}
+	return editTarget{order: order, token: token, catalog: catalog}, true
 }
 
-// NewEdit arma el handler para GET/POST /edit?id=<id_orden>
-// - ordersCol: colección "orders" de Mongo
+// NewEditForm arma el handler de GET /orders/{id}/edit?token=<token_del_comprador>:
+// el form de edición con los datos actuales del pedido.
+// Sólo quien tiene el token que se entregó en el checkout puede ver o modificar el pedido.
+// - orders: repositorio de pedidos activos
+// - products: repositorio del catálogo (precios y productos que se pueden agregar)
 // - tpl: template HTML para la vista de edición
-func NewEdit(ordersCol *mongo.Collection, tpl *template.Template) http.HandlerFunc {
-	// devolvemos una función que cumple con http.HandlerFunc
+func NewEditForm(orders store.OrderStore, products store.ProductStore, tpl *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// 1) Leer id del query: /edit?id=...
-		idStr := r.URL.Query().Get("id") // obtenemos el valor de ?id=...
-		if idStr == "" {
-			// si no vino id, devolvemos error 400
-			http.Error(w, "falta parámetro id", http.StatusBadRequest)
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		t, ok := loadEditable(ctx, w, r, orders, products)
+		if !ok {
 			return
 		}
+		qtys := map[string]int{}
+		for _, it := range t.order.Items {
+			qtys[it.ProductID.Hex()] += it.Qty
+		}
+		render(w, r, tpl, "edit.tmpl", http.StatusOK, editView{
+			Order:   t.order,
+			Token:   t.token,
+			Lines:   editLines(t.catalog, t.order, qtys, nil),
+			Sectors: validate.IglooSectors,
+			CSRF:    csrf.Token(r),
+		})
+	}
+}
 
-		// 2) Convertir el id string (hex) a ObjectID
-		objID, err := primitive.ObjectIDFromHex(idStr) // parsea el string a ObjectID
-		if err != nil {
-			// si el formato no es válido, devolvemos error 400
-			http.Error(w, "id inválido", http.StatusBadRequest)
+// NewEditSubmit arma el handler de POST /orders/{id}/edit?token=<token_del_comprador>.
+// Se pueden cambiar nombre, dirección y los ítems (agregar, quitar, cambiar cantidades);
+// los precios y el total se vuelven a calcular en el servidor, como en el checkout.
+func NewEditSubmit(orders store.OrderStore, products store.ProductStore, tpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// contexto con timeout de 5 segundos (Edit hace una transacción)
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		t, ok := loadEditable(ctx, w, r, orders, products)
+		if !ok {
 			return
 		}
+		order, token := t.order, t.token
+
+		// parseamos el body del form (application/x-www-form-urlencoded)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "error al leer formulario", http.StatusBadRequest)
+			return
+		}
+
+		// leemos los campos que permitimos editar
+		edited := order
+		edited.BuyerName = strings.TrimSpace(r.FormValue("buyer_name")) // nuevo nombre
+		edited.Address = strings.TrimSpace(r.FormValue("address"))      // nueva dirección
+		edited.IglooSector = strings.TrimSpace(r.FormValue("igloo_sector"))
 
-		// 3) Crear contexto con timeout de 3 segundos
-		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second) // contexto con timeout
-		defer cancel()                                                 // liberamos el contexto al salir
+		// mismas reglas que el checkout (el email no se edita)
+		fieldErrs := validate.Errors{}
+		fieldErrs.Check("buyer_name", validate.Text("El nombre", edited.BuyerName, validate.MaxNameLen))
+		fieldErrs.Check("address", validate.Text("La dirección", edited.Address, validate.MaxAddressLen))
+		fieldErrs.Check("igloo_sector", validate.IglooSector(edited.IglooSector))
 
-		// 4) Buscar la orden en Mongo por _id
-		var order Order
-		err = ordersCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&order) // busca y decodifica en order
+		// ítems: mismos campos qty_<id> que el checkout; precios y total salen del servidor
+		// lo que el pedido ya tiene reservado cuenta como disponible, y sus productos se pueden
+		// conservar aunque el admin los haya desactivado
+		owned := map[primitive.ObjectID]int{}
+		for _, it := range order.Items {
+			owned[it.ProductID] += it.Qty
+		}
+		lines, err := readLineItems(ctx, products, r.PostForm, owned)
 		if err != nil {
-			// si no se encuentra o hay error, devolvemos 404
-			http.Error(w, "pedido no encontrado", http.StatusNotFound)
+			slog.ErrorContext(ctx, "no se pudieron leer los productos", "order_id", order.ID.Hex(), "err", err)
+			http.Error(w, "error al obtener productos", http.StatusInternalServerError)
 			return
 		}
+		edited.Items, edited.Total = lines.Items, lines.Total
 
-		// 5) Regla de negocio: solo se puede editar si el estado es "nuevo"
-		if order.Status != "nuevo" {
-			// devolvemos 400 si el pedido no está en estado editable
-			http.Error(w, "solo se pueden editar pedidos con estado 'nuevo'", http.StatusBadRequest)
-			return
+		// rerender: volvemos a mostrar el form con lo que mandó el comprador y los errores
+		rerender := func(status int, lineErrors map[string]string, msgs ...string) {
+			render(w, r, tpl, "edit.tmpl", status, editView{
+				Order:       edited,
+				Token:       token,
+				Lines:       editLines(t.catalog, order, lines.Qtys, lineErrors),
+				Sectors:     validate.IglooSectors,
+				FieldErrors: fieldErrs,
+				Errors:      msgs,
+				CSRF:        csrf.Token(r),
+			})
 		}
 
-		// 6) Si es GET → mostramos el formulario con los datos actuales
-		if r.Method == http.MethodGet {
-			// indicamos que vamos a devolver HTML
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-			// ejecutamos el template pasando la order como data
-			if err := tpl.Execute(w, order); err != nil {
-				// si el template falla, devolvemos 500
-				http.Error(w, "error al renderizar plantilla", http.StatusInternalServerError)
-			}
-			// salimos del handler
+		switch {
+		case lines.Errors.Any():
+			slog.DebugContext(ctx, "edición rechazada por líneas inválidas", "order_id", order.ID.Hex(), "problems", lines.Problems)
+			rerender(http.StatusBadRequest, lines.Errors, append([]string{"No pudimos aplicar los cambios por estos productos:"}, lines.Problems...)...)
+			return
+		case len(edited.Items) == 0:
+			rerender(http.StatusBadRequest, nil, "El pedido tiene que tener al menos un producto.")
+			return
+		case fieldErrs.Any():
+			rerender(http.StatusBadRequest, nil, "Revisá los datos marcados.")
+			return
+		}
+		if err := edited.Validate(); err != nil {
+			rerender(http.StatusBadRequest, nil, "Pedido inválido: "+err.Error())
 			return
 		}
 
-		// 7) Si es POST → procesamos el formulario y actualizamos la orden
-		if r.Method == http.MethodPost {
-			// parseamos el body del form (application/x-www-form-urlencoded)
-			if err := r.ParseForm(); err != nil {
-				// si falla el parse, devolvemos 400
-				http.Error(w, "error al leer formulario", http.StatusBadRequest)
-				return
-			}
-
-			// leemos los campos que permitimos editar
-			newBuyerName := r.FormValue("buyer_name") // nuevo nombre
-			newAddress := r.FormValue("address")      // nueva dirección
-
-			// armamos el documento de actualización con $set
-			update := bson.M{
-				"$set": bson.M{
-					"buyer_name": newBuyerName, // actualizamos nombre del comprador
-					"address":    newAddress,   // actualizamos dirección
-					// si quisieras, acá se agregan más campos editables
-				},
-			}
-
-			// ejecutamos la actualización por _id
-			_, err := ordersCol.UpdateByID(ctx, objID, update) // update de la orden
-			if err != nil {
-				// si hay error al actualizar, devolvemos 500
-				http.Error(w, "error al actualizar pedido", http.StatusInternalServerError)
-				return
-			}
-
-			// después de actualizar, redirigimos al panel de pedidos
-			http.Redirect(w, r, "/orders", http.StatusFound) // 302 redirect
+		// Edit ajusta reservas de stock y actualiza sólo si el pedido sigue en "nuevo" (compare-and-swap)
+		err = orders.Edit(ctx, edited)
+		var stockErr *store.StockError
+		switch {
+		case errors.As(err, &stockErr):
+			rerender(http.StatusConflict, shortageMessages(stockErr), "No pudimos aplicar los cambios: revisá las cantidades marcadas.")
+			return
+		case errors.Is(err, store.ErrNotEditable):
+			// el admin lo pasó a "preparando" mientras el comprador editaba
+			http.Error(w, "tu pedido ya se está preparando y no se puede modificar", http.StatusConflict)
+			return
+		case errors.Is(err, store.ErrNotFound):
+			// el pedido desapareció entre el GET y el POST (por ejemplo, ya se entregó)
+			http.Error(w, "pedido no encontrado", http.StatusNotFound)
+			return
+		case err != nil:
+			slog.ErrorContext(ctx, "error al actualizar el pedido", "order_id", order.ID.Hex(), "err", err)
+			http.Error(w, "error al actualizar pedido", http.StatusInternalServerError)
 			return
 		}
 
-		// 8) Si el método no es GET ni POST → devolvemos 405
-		http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+		// después de actualizar, redirigimos al estado del pedido (link privado con el token)
+		http.Redirect(w, r, statusURL(order.ID, token), http.StatusSeeOther) // 303 (PRG)
+	}
+}
+
+// NewLegacyEdit redirige la ruta vieja GET /edit?id=<id>&token=... (links ya entregados a
+// los compradores) a /orders/{id}/edit. Sólo atiende GET: los forms viejos que posteaban a
+// /edit no traen token CSRF, así que un POST pendiente no se puede aceptar; recibe 405 y el
+// comprador vuelve a abrir el link para editar.
+func NewLegacyEdit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oid, err := primitive.ObjectIDFromHex(r.URL.Query().Get("id"))
+		if err != nil {
+			http.Error(w, "id inválido", http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, editURL(oid, r.URL.Query().Get("token")), http.StatusPermanentRedirect)
 	}
 }
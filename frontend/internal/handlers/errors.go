@@ -0,0 +1,45 @@
+// errors.go — páginas de error (error.tmpl) para 403, 404 y 405
+
+package handlers
+
+import (
+	"html/template" // *template.Template
+	"net/http"      // códigos de estado
+)
+
+// errorView: datos de error.tmpl.
+type errorView struct {
+	Status  int    // código HTTP (se muestra grande arriba)
+	Title   string // título de la página
+	Message string // explicación para el comprador
+	Allow   string // métodos que acepta la ruta (sólo en 405)
+}
+
+// errorPages: texto de cada código que tiene página propia.
+var errorPages = map[int]errorView{
+	http.StatusForbidden: {
+		Title:   "No pudimos verificar el formulario",
+		Message: "Por seguridad no aceptamos este envío: el formulario venció o llegó desde otro sitio. Volvé a la página, recargala y probá de nuevo.",
+	},
+	http.StatusNotFound: {
+		Title:   "Página no encontrada",
+		Message: "La dirección que buscás no existe (o se mudó a otro iglú).",
+	},
+	http.StatusMethodNotAllowed: {
+		Title:   "Método no permitido",
+		Message: "Esta dirección existe, pero no acepta ese tipo de pedido.",
+	},
+}
+
+// renderError renderiza error.tmpl con el código status. Para 405 muestra el header Allow
+// (si ya está puesto). Un código sin página propia cae en el texto estándar de net/http.
+func renderError(w http.ResponseWriter, r *http.Request, tmpl *template.Template, status int) {
+	v, ok := errorPages[status]
+	if !ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	v.Status = status
+	v.Allow = w.Header().Get("Allow")
+	render(w, r, tmpl, "error.tmpl", status, v)
+}
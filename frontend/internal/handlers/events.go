@@ -0,0 +1,210 @@
+// events.go — Server-Sent Events del tablero (GET /orders/events) y del estado de un pedido
+// (GET /status/:id/events?token=...). Los cambios vienen del change stream de "orders" a
+// través de live.Hub; cada evento trae sólo la fila (o el bloque) que cambió, ya renderizado
+// con las mismas plantillas que la página, así el JS sólo reemplaza HTML.
+// Sin JS las páginas siguen con <meta refresh> (dentro de <noscript>).
+
+package handlers
+
+import (
+	"bytes"         // bytes.Buffer: renderizar el fragmento antes de mandarlo
+	"context"       // timeout de la lectura inicial
+	"encoding/json" // data de cada evento (el HTML viaja escapado, en una sola línea)
+	"errors"        // errors.Is: store.ErrNotFound
+	"fmt"           // fmt.Fprintf: formato del protocolo SSE
+	"html/template" // *template.Template
+	"log/slog"      // slog: errores con el id de la request
+	"net/http"      // handlers HTTP
+	"time"          // heartbeat y timeouts
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access"  // token del comprador
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"    // token CSRF del form de cancelación
+	"github.com/gastonduartem/Challenge-1/frontend/internal/live"    // fan-out del change stream
+	"github.com/gastonduartem/Challenge-1/frontend/internal/metrics" // errores de plantilla
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"  // Order
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"   // repositorio de pedidos
+	"go.mongodb.org/mongo-driver/bson/primitive"                     // ObjectID
+)
+
+// heartbeat: cada cuánto mandamos un comentario para que proxies y navegador no corten la conexión.
+const heartbeat = 25 * time.Second
+
+// sseRetry: milisegundos que espera el navegador antes de reconectar (campo retry: del protocolo).
+const sseRetry = 3000
+
+// liveRow: data de un evento "order" / "status" (fila o bloque ya renderizado).
+type liveRow struct {
+	ID   string `json:"id"`
+	HTML string `json:"html,omitempty"`
+}
+
+// sseStream prepara la respuesta text/event-stream; false si el ResponseWriter no permite flush.
+func sseStream(w http.ResponseWriter) (http.Flusher, bool) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming no soportado", http.StatusInternalServerError)
+		return nil, false
+	}
+	// El stream dura lo que el navegador quiera: sin el ReadTimeout/WriteTimeout del http.Server
+	// (el ReadTimeout también cuenta: al vencer cancela el contexto de la request)
+	rc := http.NewResponseController(w)
+	if err := errors.Join(rc.SetReadDeadline(time.Time{}), rc.SetWriteDeadline(time.Time{})); err != nil {
+		slog.Warn("sse: no se pudieron quitar los timeouts", "err", err)
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // nginx: no bufferear el stream
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	f.Flush()
+	return f, true
+}
+
+// writeEvent escribe un evento SSE con data en JSON (una sola línea) y hace flush.
+func writeEvent(w http.ResponseWriter, f http.Flusher, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+	f.Flush()
+	return nil
+}
+
+// fragment renderiza la sub-plantilla name a string.
+func fragment(tmpl *template.Template, name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// streamChanges atiende la conexión SSE: por cada cambio del hub llama a send (que decide si
+// le importa y qué mandar) y cada heartbeat manda un comentario. Termina cuando el cliente se
+// va, el hub lo da de baja (canal cerrado: el navegador reconecta y resincroniza) o send
+// devuelve done/err.
+func streamChanges(w http.ResponseWriter, r *http.Request, f http.Flusher, changes <-chan store.OrderChange, send func(store.OrderChange) (done bool, err error)) {
+	tick := time.NewTicker(heartbeat)
+	defer tick.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case c, ok := <-changes:
+			if !ok {
+				return
+			}
+			done, err := send(c)
+			if err != nil || done {
+				return
+			}
+		case <-tick.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			f.Flush()
+		}
+	}
+}
+
+// NewOrdersEvents construye el handler de GET /orders/events (tablero en vivo).
+// Eventos:
+//   - order: {id, html} con la fila <tr> del pedido (nuevo o cambiado)
+//   - remove: {id} cuando el pedido sale de "orders" (entregado o cancelado)
+func NewOrdersEvents(hub *live.Hub, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		changes, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+
+		f, ok := sseStream(w)
+		if !ok {
+			return
+		}
+		streamChanges(w, r, f, changes, func(c store.OrderChange) (bool, error) {
+			if c.Order == nil {
+				return false, writeEvent(w, f, "remove", liveRow{ID: c.OrderID.Hex()})
+			}
+			row, err := fragment(tmpl, "order_row", *c.Order)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "error de plantilla", "template", "order_row", "err", err)
+				metrics.TemplateError("order_row")
+				return true, err
+			}
+			return false, writeEvent(w, f, "order", liveRow{ID: c.OrderID.Hex(), HTML: row})
+		})
+	}
+}
+
+// NewStatusEvents construye el handler de GET /status/<id>/events?token=... (estado en vivo).
+// Eventos:
+//   - status: {id, html} con el bloque "status_live" (estado, ítems, total, links de edición);
+//     se manda uno apenas conecta, por si el pedido cambió entre la página y el stream
+//   - closed: {id} cuando el pedido sale de "orders"; el navegador recarga y ve el estado final
+//
+// Si el pedido no está activo o el token no es el del comprador responde 204 (EventSource no
+// vuelve a reconectar): la misma respuesta en los dos casos, así el stream no revela qué ids
+// existen.
+func NewStatusEvents(orders store.OrderStore, hub *live.Hub, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idHex := r.PathValue("id") // {id} del patrón GET /status/{id}/events
+		oid, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		token := r.URL.Query().Get("token")
+
+		// Nos suscribimos antes de leer el pedido: así no se pierde un cambio entre la lectura y el stream
+		changes, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		order, err := orders.FindByID(ctx, oid)
+		cancel()
+		if err == nil && !access.Match(order.AccessTokenHash, token) {
+			err = store.ErrNotFound // token ausente o incorrecto → igual que si no existiera
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			w.WriteHeader(http.StatusNoContent) // ya no está activo (o no es suyo): nada que seguir
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "error al buscar el pedido", "order_id", idHex, "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// El form de cancelación del fragmento lleva el token CSRF: se pide antes de mandar los
+		// headers (la página ya emitió la cookie, pero si no estuviera Token la emite acá)
+		csrfToken := csrf.Token(r)
+		f, ok := sseStream(w)
+		if !ok {
+			return
+		}
+		send := func(o models.Order) (bool, error) {
+			html, err := fragment(tmpl, "status_live", statusViewFromOrder(o, token, csrfToken))
+			if err != nil {
+				slog.ErrorContext(r.Context(), "error de plantilla", "template", "status_live", "err", err)
+				metrics.TemplateError("status_live")
+				return true, err
+			}
+			return false, writeEvent(w, f, "status", liveRow{ID: idHex, HTML: html})
+		}
+		if done, err := send(order); done || err != nil {
+			return
+		}
+		streamChanges(w, r, f, changes, func(c store.OrderChange) (bool, error) {
+			if c.OrderID != oid {
+				return false, nil
+			}
+			if c.Order == nil {
+				return true, writeEvent(w, f, "closed", liveRow{ID: idHex})
+			}
+			return send(*c.Order)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// health.go — liveness (GET /healthz) y readiness (GET /readyz)
+// /healthz sólo dice que el proceso responde; /readyz dice si puede atender pedidos
+// (Mongo alcanzable, primario del replica set, plantillas cargadas), con el detalle en JSON.
+
+package handlers
+
+import (
+	"context"       // timeout de los chequeos
+	"encoding/json" // respuesta de /readyz
+	"net/http"      // handlers HTTP
+	"time"          // timeout y duración de cada chequeo
+)
+
+// readyTimeout: tiempo máximo para todos los chequeos de /readyz juntos.
+const readyTimeout = 2 * time.Second
+
+// ReadyCheck: un chequeo de /readyz. Run devuelve nil si está todo bien.
+type ReadyCheck struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// checkResult: resultado de un chequeo en el JSON de /readyz.
+type checkResult struct {
+	OK         bool   `json:"ok"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// readyReport: cuerpo de /readyz.
+type readyReport struct {
+	Status string                 `json:"status"` // "ok" o "fail"
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// NewHealth construye el handler de GET /healthz (liveness): siempre 200 mientras el proceso
+// atienda requests. No toca la DB: si Mongo se cae, reiniciar el contenedor no lo arregla.
+func NewHealth() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("OK"))
+	}
+}
+
+// NewReady construye el handler de GET /readyz (readiness): corre checks en orden y responde
+// 200 si pasan todos o 503 si falla alguno, con el resultado de cada uno en JSON.
+func NewReady(checks []ReadyCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+
+		report := readyReport{Status: "ok", Checks: make(map[string]checkResult, len(checks))}
+		status := http.StatusOK
+		for _, c := range checks {
+			start := time.Now()
+			err := c.Run(ctx)
+			res := checkResult{OK: err == nil, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Error = err.Error()
+				report.Status = "fail"
+				status = http.StatusServiceUnavailable
+			}
+			report.Checks[c.Name] = res
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
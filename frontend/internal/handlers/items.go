@@ -0,0 +1,176 @@
+// items.go — lectura de los campos qty_<productID> de un form (checkout y edición)
+
+package handlers
+
+import (
+	"context" // timeout de las consultas a products
+	"fmt"     // fmt.Sprintf: mensajes de stock por producto
+	"net/url" // url.Values: el form ya parseado
+	"sort"    // sort.Strings: recorrer las líneas en orden fijo
+	"strconv" // strconv.Atoi: cantidad en texto → int
+	"strings" // strings.HasPrefix / TrimPrefix: detectar qty_<id>
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"   // Item
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"    // repositorio de productos
+	"github.com/gastonduartem/Challenge-1/frontend/internal/validate" // límites de cantidad y totales sin overflow
+	"go.mongodb.org/mongo-driver/bson/primitive"                      // ObjectID
+)
+
+// lineItems: ítems armados a partir del form, con precios leídos del servidor.
+type lineItems struct {
+	Items []models.Item  // ítems válidos (snapshot de nombre y precio)
+	Total int            // suma de subtotales
+	Qtys  map[string]int // cantidades pedidas por ID hex (para volver a mostrar el form)
+
+	Errors   validate.Errors // errores por línea (ID hex → mensaje); si hay alguno, el form se rechaza
+	Problems []string        // las mismas líneas fallidas, con nombre de producto (resumen arriba del form)
+	Limits   map[string]int  // cantidad máxima que se puede pedir por producto (para el max de los inputs)
+}
+
+// fail registra el error de una línea: por ID hex (para la tarjeta) y en el resumen (con el nombre).
+func (l *lineItems) fail(idHex, name, msg string) {
+	if _, dup := l.Errors[idHex]; dup {
+		return
+	}
+	l.Errors[idHex] = msg
+	l.Problems = append(l.Problems, name+": "+msg)
+}
+
+// readLineItems recorre los campos qty_<idHex> del form y arma los ítems del pedido.
+// Nombre y precio SIEMPRE salen del store (nunca del navegador); el total se calcula acá.
+// Una cantidad vacía o 0 significa "no lo quiero" (en edición: quitar el ítem); cualquier otra
+// línea que no se pueda armar (cantidad inválida, id roto, producto inexistente o inactivo)
+// queda en Errors/Problems en vez de descartarse en silencio. Qtys, Errors y Limits van por
+// ID hex canónico; el mismo producto en dos campos (qty_<hex> y qty_<HEX>) es un error.
+// Cada cantidad se compara con lo disponible (stock - reserved): es el mismo límite que aplica
+// la reserva atómica del store, pero acá el comprador ve el error por línea antes de la transacción.
+// owned: unidades que el pedido ya tiene reservadas por producto (en edición; nil en el checkout).
+// Esas unidades también cuentan como disponibles, y un producto inactivo se puede conservar
+// (sin aumentar) si el pedido ya lo tenía.
+// Los productos se leen con una sola consulta (FindByIDs); si esa consulta falla devuelve el error.
+func readLineItems(ctx context.Context, products store.ProductStore, form url.Values, owned map[primitive.ObjectID]int) (lineItems, error) {
+	out := lineItems{Qtys: map[string]int{}, Errors: validate.Errors{}, Limits: map[string]int{}}
+
+	// Ordenamos las keys: los maps no tienen orden y queremos ítems y errores siempre iguales
+	keys := make([]string, 0, len(form))
+	for key, vals := range form {
+		if strings.HasPrefix(key, "qty_") && len(vals) > 0 { // sólo procesamos campos que empiezan con "qty_"
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// 1) Parseamos cantidades e ids; juntamos los ids a buscar
+	type pending struct {
+		idHex string
+		oid   primitive.ObjectID
+		qty   int
+	}
+	var todo []pending
+	var ids []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	for _, key := range keys {
+		raw := strings.TrimSpace(form.Get(key))
+		// key = "qty_<idHex>" → extraemos la parte del ObjectID en hex
+		idHex := strings.TrimPrefix(key, "qty_")
+		if raw == "" || raw == "0" { // producto no elegido (o quitado en la edición)
+			continue
+		}
+
+		qty, err := strconv.Atoi(raw) // cantidad en texto → int
+		if err != nil {
+			out.fail(idHex, "Producto "+idHex, "La cantidad no es un número.")
+			continue
+		}
+
+		// primitive.ObjectIDFromHex convierte un string hex de 24 chars al tipo ObjectID de Mongo
+		oid, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			out.fail(idHex, "Producto "+idHex, "No es un producto válido.")
+			continue
+		}
+		// Forma canónica (minúsculas): qty_<hex> y qty_<HEX> son el mismo producto, y dos líneas
+		// del mismo producto se saltearían el tope por línea y el de stock
+		idHex = oid.Hex()
+		if seen[oid] {
+			out.fail(idHex, "Producto "+idHex, "El producto aparece más de una vez en el pedido.")
+			continue
+		}
+		seen[oid] = true
+		out.Qtys[idHex] = qty
+		todo = append(todo, pending{idHex: idHex, oid: oid, qty: qty})
+		ids = append(ids, oid)
+	}
+
+	// 2) Un solo round trip al store para traer nombre y precio "confiables" (server-side)
+	found, err := products.FindByIDs(ctx, ids)
+	if err != nil {
+		return out, err
+	}
+
+	// 3) Armamos los ítems con lo que devolvió el store
+	for _, l := range todo {
+		p, ok := found[l.oid]
+		switch {
+		case out.Errors[l.idHex] != "": // repetido: ya quedó rechazado
+			continue
+		case !ok:
+			out.fail(l.idHex, "Producto "+l.idHex, "Este producto ya no existe.")
+			continue
+		case !p.Active && owned[l.oid] == 0:
+			out.fail(l.idHex, p.Name, "Ya no está a la venta.")
+			continue
+		}
+
+		// Tope de stock: lo disponible + lo que este pedido ya tenía reservado
+		limit := owned[l.oid]
+		if p.Active {
+			limit += p.Available()
+		}
+		out.Limits[l.idHex] = min(limit, validate.MaxQty)
+
+		// Cantidad fuera de rango (ej: qty_x=99999999 o negativa) → error de la línea
+		if err := validate.Quantity(l.qty); err != nil {
+			out.fail(l.idHex, p.Name, err.Error())
+			continue
+		}
+		if l.qty > limit {
+			msg := fmt.Sprintf("Stock insuficiente: pediste %d, quedan %d.", l.qty, limit)
+			if limit == 0 {
+				msg = "Agotado."
+			}
+			out.fail(l.idHex, p.Name, msg)
+			continue
+		}
+
+		// subtotal por ítem = precio * cantidad, y lo acumulamos al total (ambos sin overflow)
+		sub, err := validate.Subtotal(p.Price, l.qty)
+		if err == nil {
+			out.Total, err = validate.Add(out.Total, sub)
+		}
+		if err != nil {
+			out.fail(l.idHex, p.Name, err.Error())
+			continue
+		}
+
+		// Armamos el Item con snapshot + ProductID (lo usa el admin para descontar stock)
+		out.Items = append(out.Items, models.Item{
+			ProductID: l.oid,      // ObjectID del producto (para auditoría/stock)
+			Name:      p.Name,     // snapshot de nombre (evita lookup futuro)
+			Qty:       l.qty,      // cantidad solicitada
+			UnitPrice: p.Price,    // snapshot de precio
+			Subtotal:  sub,        // subtotal calculado
+			Category:  p.Category, // snapshot de categoría (reportes)
+		})
+	}
+	return out, nil
+}
+
+// shortageMessages convierte los faltantes de stock en mensajes por producto (ID hex → texto).
+func shortageMessages(se *store.StockError) map[string]string {
+	msgs := map[string]string{}
+	for _, sh := range se.Shortages {
+		msgs[sh.ProductID.Hex()] = fmt.Sprintf("Stock insuficiente para %s: pediste %d, quedan %d.", sh.Name, sh.Requested, sh.Available)
+	}
+	return msgs
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingProducts cuenta las consultas a products (cada una sería un round trip a Mongo).
+// Con perLine, FindByIDs se resuelve con un FindByID por línea (una consulta por ítem del carrito).
+type countingProducts struct {
+	store.ProductStore
+	perLine bool
+	queries int
+}
+
+func (c *countingProducts) FindByID(ctx context.Context, id primitive.ObjectID) (models.Product, error) {
+	c.queries++
+	return c.ProductStore.FindByID(ctx, id)
+}
+
+func (c *countingProducts) FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Product, error) {
+	if !c.perLine {
+		c.queries++
+		return c.ProductStore.FindByIDs(ctx, ids)
+	}
+	out := make(map[primitive.ObjectID]models.Product, len(ids))
+	for _, id := range ids {
+		p, err := c.FindByID(ctx, id)
+		if err != nil {
+			continue // inexistente: no aparece en el map
+		}
+		out[id] = p
+	}
+	return out, nil
+}
+
+func BenchmarkReadLineItems(b *testing.B) {
+	const lines = 200 // carrito grande
+	mem := store.NewMemory()
+	form := url.Values{}
+	for i := range lines {
+		id := mem.PutProduct(models.Product{Name: "Producto " + strconv.Itoa(i), Price: 1000 + i, Stock: 1000, Active: true})
+		form.Set("qty_"+id.Hex(), "3")
+	}
+
+	for _, bc := range []struct {
+		name    string
+		perLine bool
+	}{{"FindByIDs", false}, {"FindByID", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			products := &countingProducts{ProductStore: mem.Stores().Products, perLine: bc.perLine}
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				out, err := readLineItems(ctx, products, form, nil)
+				if err != nil || len(out.Items) != lines {
+					b.Fatalf("readLineItems: %d ítems, err %v", len(out.Items), err)
+				}
+			}
+			b.ReportMetric(float64(products.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// links.go — URLs privadas del comprador (llevan el token de acceso al pedido)
+
+package handlers
+
+import (
+	"net/url" // url.Values: escapar los parámetros del query string
+
+	"go.mongodb.org/mongo-driver/bson/primitive" // ObjectID del pedido
+)
+
+// statusURL arma el link a /status/<id> con el token del comprador.
+func statusURL(id primitive.ObjectID, token string) string {
+	return "/status/" + id.Hex() + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// editURL arma el link a /orders/<id>/edit con el token del comprador (GET muestra el form, POST lo guarda).
+func editURL(id primitive.ObjectID, token string) string {
+	return "/orders/" + id.Hex() + "/edit?" + url.Values{"token": {token}}.Encode()
+}
+
+// confirmationURL arma el link a /orders/<id>/confirmation con el token del comprador.
+func confirmationURL(id primitive.ObjectID, token string) string {
+	return "/orders/" + id.Hex() + "/confirmation?" + url.Values{"token": {token}}.Encode()
+}
+
+// cancelURL arma el action del form de cancelación (POST /orders/<id>/cancel) con el token.
+func cancelURL(id primitive.ObjectID, token string) string {
+	return "/orders/" + id.Hex() + "/cancel?" + url.Values{"token": {token}}.Encode()
+}
+
+// statusEventsURL arma el stream SSE de /status/<id>/events con el token del comprador.
+func statusEventsURL(id primitive.ObjectID, token string) string {
+	return "/status/" + id.Hex() + "/events?" + url.Values{"token": {token}}.Encode()
+}
@@ -0,0 +1,86 @@
+// product.go — handler SSR de la ficha de producto (GET /products/:id)
+// Descripción completa, imagen grande, disponibilidad (stock - reservado) y form para sumarlo al carrito.
+
+package handlers
+
+import (
+	"context"       // timeout para la consulta a la DB
+	"errors"        // errors.Is: distinguir store.ErrNotFound
+	"fmt"           // fmt.Sprintf: texto de disponibilidad
+	"html/template" // *template.Template
+	"log/slog"      // slog: errores con el id de la request
+	"net/http"      // servidor HTTP estándar
+	"time"          // duración del timeout
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"     // token CSRF del form
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"   // modelo de producto
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"    // repositorio de productos
+	"github.com/gastonduartem/Challenge-1/frontend/internal/validate" // tope de cantidad del form
+	"go.mongodb.org/mongo-driver/bson/primitive"                      // ObjectID de Mongo
+)
+
+// ProductView: datos que consume product.tmpl.
+type ProductView struct {
+	models.Product                 // campos promovidos: .Name, .Price, .Description, .ImagePath, .Stock, ...
+	UploadsBase    string          // prefijo público para la imagen
+	Availability   string          // texto de disponibilidad ("Hay stock", "Últimas 3 unidades", "Agotado")
+	InStock        bool            // false → no mostramos el form de agregar
+	MaxQty         int             // tope del input de cantidad (disponible, sin pasar de validate.MaxQty)
+	CategoryInfo   models.Category // categoría (Slug vacío si el producto no tiene)
+	CSRF           string          // token CSRF del form de agregar al carrito
+}
+
+// availability traduce las unidades disponibles (stock - reserved) en el texto que ve el comprador.
+func availability(available int) string {
+	switch {
+	case available <= 0:
+		return "Agotado"
+	case available == 1:
+		return "¡Última unidad!"
+	case available <= models.LowStockThreshold:
+		return fmt.Sprintf("Últimas %d unidades", available)
+	default:
+		return "Hay stock"
+	}
+}
+
+// NewProduct construye el handler de GET /products/<id>.
+// Productos inexistentes o inactivos (is_active = false) responden 404, igual que la home que no los lista.
+func NewProduct(products store.ProductStore, uploadsBase string, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idHex := r.PathValue("id") // {id} del patrón GET /products/{id}
+		oid, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			renderError(w, r, tmpl, http.StatusNotFound)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		p, err := products.FindByID(ctx, oid)
+		if err == nil && !p.Active {
+			err = store.ErrNotFound // inactivo → para la tienda no existe
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			renderError(w, r, tmpl, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "error al buscar el producto", "product_id", idHex, "err", err)
+			http.Error(w, "error al obtener el producto", http.StatusInternalServerError)
+			return
+		}
+
+		cat, _ := models.CategoryBySlug(p.Category)
+		render(w, r, tmpl, "product.tmpl", http.StatusOK, ProductView{
+			CategoryInfo: cat,
+			Product:      p,
+			UploadsBase:  uploadsBase,
+			Availability: availability(p.Available()),
+			InStock:      !p.SoldOut(),
+			MaxQty:       p.OrderLimit(validate.MaxQty),
+			CSRF:         csrf.Token(r),
+		})
+	}
+}
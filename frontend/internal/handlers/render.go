@@ -0,0 +1,30 @@
+// render.go — helper común para renderizar plantillas SSR
+
+package handlers
+
+import (
+	"bytes"         // bytes.Buffer: armamos el HTML en memoria antes de enviarlo
+	"html/template" // *template.Template
+	"log/slog"      // slog: registrar errores de plantilla (con el id de la request)
+	"net/http"      // http.ResponseWriter / códigos de estado
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/metrics" // contador de errores de plantilla
+)
+
+// render ejecuta la plantilla name en un buffer y, si salió bien, la escribe con el código status.
+// Si la plantilla falla (campo inexistente, tipo incorrecto, etc.) se loguea y se responde 500,
+// sin mandar HTML a medias al cliente.
+func render(w http.ResponseWriter, r *http.Request, tmpl *template.Template, name string, status int, data any) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		slog.ErrorContext(r.Context(), "error de plantilla", "template", name, "err", err)
+		metrics.TemplateError(name)
+		http.Error(w, "error al renderizar la página", http.StatusInternalServerError)
+		return
+	}
+
+	// Cabecera de tipo de contenido: HTML con UTF-8
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w) // WriteTo copia el buffer al cliente; descartamos (n, err)
+}
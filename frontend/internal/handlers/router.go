@@ -0,0 +1,137 @@
+// router.go — rutas de la tienda con los patrones de http.ServeMux (Go 1.22)
+// Cada patrón lleva método y comodines ("GET /status/{id}"): el mux responde 405 a un método
+// que la ruta no acepta y los handlers leen los segmentos con r.PathValue. Lo que no matchea
+// ninguna ruta (typos, links viejos) o llega con otro método se contesta con error.tmpl en
+// vez del texto plano del mux.
+
+package handlers
+
+import (
+	"html/template" // *template.Template
+	"net/http"      // ServeMux con patrones
+	"strings"       // strings.Cut: separar método y ruta del patrón
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"    // token de los forms POST
+	"github.com/gastonduartem/Challenge-1/frontend/internal/live"    // hub de cambios de "orders" (SSE)
+	"github.com/gastonduartem/Challenge-1/frontend/internal/metrics" // métricas por ruta y /metrics
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session" // cookie de sesión del carrito
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"   // repositorios
+)
+
+// unmatchedRoute: label de ruta en las métricas para las requests que no matchean ningún patrón.
+const unmatchedRoute = "unmatched"
+
+// RouterDeps: todo lo que necesitan los handlers de la tienda.
+type RouterDeps struct {
+	Stores      store.Stores       // repositorios sobre la DB (checkout, carrito, pedidos, edición)
+	Catalog     store.ProductStore // catálogo de la home, las categorías y la ficha (el cache en memoria)
+	Hub         *live.Hub          // cambios de "orders" para los streams SSE
+	Sessions    *session.Manager   // cookie de sesión del carrito
+	CSRF        *csrf.Protector    // verifica el token de los forms en cada POST
+	Templates   *template.Template // todas las plantillas (home.tmpl, edit.tmpl, error.tmpl, ...)
+	UploadsBase string             // prefijo público de las imágenes
+	Ready       []ReadyCheck       // chequeos de /readyz
+}
+
+// NewRouter registra las rutas de la tienda y devuelve el handler del servidor.
+// Cada ruta pasa por las métricas HTTP con su patrón sin el método como label
+// ("/status/{id}"): los ids de las URLs no crean una serie por pedido o producto.
+// Cada ruta va además detrás de d.CSRF: un POST sin el token del form se rechaza con la
+// página 403. Se aplica por ruta y no al router entero, así lo que no matchea sigue
+// contestando 404/405 (y los GET reciben el token para armar sus forms).
+func NewRouter(d RouterDeps) http.Handler {
+	mux := http.NewServeMux()
+	tmpls := d.Templates
+	forbidden := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		renderError(w, r, tmpls, http.StatusForbidden)
+	})
+	handle := func(pattern string, h http.HandlerFunc) {
+		_, route, _ := strings.Cut(pattern, " ")
+		mux.Handle(pattern, metrics.Instrument(route, d.CSRF.Protect(h, forbidden)))
+	}
+	homeTmpl := tmpls.Lookup("home.tmpl")
+	board := &OrdersDeps{Orders: d.Stores.Orders, Tpl: tmpls.Lookup("orders_board.tmpl")}
+
+	// Catálogo: {$} → sólo "/" exacto (antes cualquier path desconocido mostraba la home)
+	handle("GET /{$}", NewHome(d.Catalog, d.UploadsBase, homeTmpl))
+	handle("GET /category/{slug}", NewCategory(d.Catalog, d.UploadsBase, homeTmpl))
+	handle("GET /products/{id}", NewProduct(d.Catalog, d.UploadsBase, tmpls))
+
+	// Carrito por sesión: GET /cart muestra; los POST cambian cantidades y vuelven a /cart
+	handle("GET /cart", NewCart(d.Stores.Carts, d.Stores.Products, d.Sessions, tmpls))
+	handle("POST /cart/add", NewCartAdd(d.Stores.Carts, d.Stores.Products, d.Sessions))
+	handle("POST /cart/update", NewCartUpdate(d.Stores.Carts, d.Sessions))
+	handle("POST /cart/remove", NewCartRemove(d.Stores.Carts, d.Sessions))
+	handle("POST /checkout", NewCheckout(d.Stores.Products, d.Stores.Orders, d.Stores.Carts, d.Sessions, d.UploadsBase, tmpls))
+
+	// Tablero público y páginas privadas del comprador (con ?token=)
+	handle("GET /orders", board.OrdersBoard)
+	handle("GET /orders/events", NewOrdersEvents(d.Hub, tmpls))
+	handle("GET /orders/{id}/confirmation", NewConfirmation(d.Stores.Orders, tmpls))
+	handle("POST /orders/{id}/cancel", NewCancel(d.Stores.Orders))
+	handle("GET /orders/{id}/edit", NewEditForm(d.Stores.Orders, d.Stores.Products, tmpls))
+	handle("POST /orders/{id}/edit", NewEditSubmit(d.Stores.Orders, d.Stores.Products, tmpls))
+	handle("GET /edit", NewLegacyEdit())
+	handle("GET /status/{id}", NewStatus(d.Stores.Orders, d.Stores.Deliveries, d.Stores.Cancellations, tmpls))
+	handle("GET /status/{id}/events", NewStatusEvents(d.Stores.Orders, d.Hub, tmpls))
+
+	// Operación: liveness, readiness y métricas (/metrics sin instrumentar)
+	handle("GET /healthz", NewHealth())
+	handle("GET /readyz", NewReady(d.Ready))
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	return &router{
+		mux:       mux,
+		unmatched: metrics.Instrument(unmatchedRoute, notMatched(mux, tmpls)),
+	}
+}
+
+// router: el mux, con las páginas de error propias para lo que no matchea.
+type router struct {
+	mux       *http.ServeMux
+	unmatched http.Handler
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Handler devuelve patrón vacío sólo cuando el mux contestaría 404 o 405
+	// (las redirecciones a la ruta limpia o con "/" final sí traen patrón)
+	if _, pattern := rt.mux.Handler(r); pattern == "" {
+		rt.unmatched.ServeHTTP(w, r)
+		return
+	}
+	rt.mux.ServeHTTP(w, r)
+}
+
+// notMatched responde con error.tmpl lo que el mux iba a contestar en texto plano.
+// Para saber si es 404 o 405 (y qué métodos acepta la ruta) corre el handler del mux
+// contra un probe que sólo guarda el código y los headers.
+func notMatched(mux *http.ServeMux, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, _ := mux.Handler(r)
+		p := &probe{header: http.Header{}}
+		h.ServeHTTP(p, r)
+		if allow := p.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+		renderError(w, r, tmpl, p.status)
+	}
+}
+
+// probe: ResponseWriter que descarta el body (ver notMatched).
+type probe struct {
+	header http.Header
+	status int
+}
+
+func (p *probe) Header() http.Header { return p.header }
+
+func (p *probe) WriteHeader(status int) {
+	if p.status == 0 {
+		p.status = status
+	}
+}
+
+func (p *probe) Write(b []byte) (int, error) {
+	p.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/live"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/session"
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testSecret = "secreto-de-prueba-bastante-largo"
+
+var csrfInput = regexp.MustCompile(`name="` + csrf.FieldName + `" value="([^"]+)"`)
+
+// newStorefront levanta el router completo sobre el store en memoria. El cliente guarda
+// las cookies (sesión y CSRF) y no sigue redirects, para poder ver los 303.
+func newStorefront(t *testing.T) (*store.Memory, *httptest.Server, *http.Client) {
+	mem := store.NewMemory()
+	sessions := session.NewManager(testSecret)
+	srv := httptest.NewServer(NewRouter(RouterDeps{
+		Stores:    mem.Stores(),
+		Catalog:   mem.Stores().Products,
+		Hub:       live.NewHub(),
+		Sessions:  sessions,
+		CSRF:      csrf.New(testSecret, sessions),
+		Templates: loadTemplates(t),
+	}))
+	t.Cleanup(srv.Close)
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	return mem, srv, client
+}
+
+// submit abre la home para sacar el token CSRF y manda form a path.
+func submit(t *testing.T, srv *httptest.Server, client *http.Client, path string, form url.Values) (*http.Response, string) {
+	t.Helper()
+	res, err := client.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	m := csrfInput.FindSubmatch(page)
+	if m == nil {
+		t.Fatal("la home no trae token CSRF")
+	}
+	form.Set(csrf.FieldName, string(m[1]))
+
+	res, err = client.PostForm(srv.URL+path, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	return res, string(body)
+}
+
+// postForm es submit exigiendo un 303; devuelve el Location de la redirección.
+func postForm(t *testing.T, srv *httptest.Server, client *http.Client, path string, form url.Values) *url.URL {
+	t.Helper()
+	res, body := submit(t, srv, client, path, form)
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("POST %s = %d, quiero 303\n%s", path, res.StatusCode, body)
+	}
+	loc, _ := res.Location()
+	return loc
+}
+
+func TestCheckoutEditCancelReserveStock(t *testing.T) {
+	mem, srv, client := newStorefront(t)
+	pid := mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Category: "pescados", Stock: 10, Active: true})
+	qty := "qty_" + pid.Hex()
+
+	// Checkout: reserva 2 y redirige a /orders/<id>/confirmation?token=...
+	loc := postForm(t, srv, client, "/checkout", url.Values{
+		"buyer_name": {"Pingu"}, "address": {"Iglú 7"}, "email": {"pingu@polo.sur"}, qty: {"2"},
+	})
+	orderPath := strings.TrimSuffix(loc.Path, "/confirmation")
+	if !strings.HasPrefix(orderPath, "/orders/") || orderPath == loc.Path {
+		t.Fatalf("checkout redirigió a %s", loc)
+	}
+	token := "?" + url.Values{"token": {loc.Query().Get("token")}}.Encode()
+	if got := mem.Reserved(pid); got != 2 {
+		t.Fatalf("reservado tras el checkout = %d, quiero 2", got)
+	}
+
+	// Edición: pasar a 5 reserva 3 más
+	postForm(t, srv, client, orderPath+"/edit"+token, url.Values{
+		"buyer_name": {"Pingu"}, "address": {"Iglú 8"}, qty: {"5"},
+	})
+	if got := mem.Reserved(pid); got != 5 {
+		t.Fatalf("reservado tras editar = %d, quiero 5", got)
+	}
+
+	// Cancelación: libera todo
+	loc = postForm(t, srv, client, orderPath+"/cancel"+token, url.Values{"reason": {"me equivoqué"}})
+	if !strings.HasPrefix(loc.Path, "/status/") {
+		t.Fatalf("cancel redirigió a %s", loc)
+	}
+	if got := mem.Reserved(pid); got != 0 {
+		t.Fatalf("reservado tras cancelar = %d, quiero 0", got)
+	}
+}
+
+func TestUnmatchedSkipsCSRF(t *testing.T) {
+	_, srv, client := newStorefront(t)
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/nope", http.StatusNotFound},
+		{http.MethodPost, "/healthz", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/orders", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/edit", http.StatusMethodNotAllowed}, // forms viejos, sin token CSRF
+		{http.MethodGet, "/debug/vars", http.StatusNotFound},    // los contadores del cache están en /metrics
+		{http.MethodPost, "/checkout", http.StatusForbidden},    // ruta real sin token
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tc.want {
+			t.Errorf("%s %s = %d, quiero %d", tc.method, tc.path, res.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestStatusEventsHidesUnknownOrders(t *testing.T) {
+	mem, srv, client := newStorefront(t)
+	pid := mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 10, Active: true})
+	order := models.Order{
+		BuyerName: "Pingu", Address: "Iglú 7", Email: "pingu@polo.sur", Status: models.StatusNuevo, Total: 5000,
+		Items:           []models.Item{{ProductID: pid, Name: "Sardinas", Qty: 1, UnitPrice: 5000, Subtotal: 5000}},
+		AccessTokenHash: access.Hash("el-token-bueno"),
+	}
+	if err := mem.Stores().Orders.Create(context.Background(), &order); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pedido inexistente y pedido existente con token incorrecto: misma respuesta
+	for _, path := range []string{
+		"/status/" + primitive.NewObjectID().Hex() + "/events?token=x",
+		"/status/" + order.ID.Hex() + "/events?token=x",
+	} {
+		res, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("GET %s = %d, quiero 204", path, res.StatusCode)
+		}
+	}
+}
+
+func TestCatalogHugePage(t *testing.T) {
+	mem, srv, client := newStorefront(t)
+	mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 10, Active: true})
+
+	// (page-1)*pageSize desbordaba y SearchProducts paniqueaba: ahora es la última página
+	for _, path := range []string{"/?page=2305843009213693953", "/?page=9223372036854775807&page_size=48"} {
+		res, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "Sardinas") {
+			t.Errorf("GET %s = %d, quiero 200 con la última página", path, res.StatusCode)
+		}
+	}
+}
+
+func TestCheckoutRejectsDuplicateProductLines(t *testing.T) {
+	mem, srv, client := newStorefront(t)
+	pid := mem.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 3, Active: true})
+
+	// El mismo ObjectID en minúsculas y mayúsculas: 3 + 3 con stock 3
+	res, _ := submit(t, srv, client, "/checkout", url.Values{
+		"buyer_name": {"Pingu"}, "address": {"Iglú 7"}, "email": {"pingu@polo.sur"},
+		"qty_" + pid.Hex(): {"3"}, "qty_" + strings.ToUpper(pid.Hex()): {"3"},
+	})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("checkout con líneas repetidas = %d, quiero 400", res.StatusCode)
+	}
+	if got := mem.Reserved(pid); got != 0 {
+		t.Errorf("reservado = %d, quiero 0", got)
+	}
+}
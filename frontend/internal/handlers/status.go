@@ -4,29 +4,113 @@ package handlers // Paquete donde agrupamos los controladores HTTP del front
 
 import (
 	"context"       // context.Context: controla cancelación/timeouts que viajan con la request
+	"errors"        // errors.Is: distinguir store.ErrNotFound de errores de la DB
 	"html/template" // html/template: motor de plantillas SSR seguro (escapa HTML)
+	"log/slog"      // log/slog: errores de la DB con el id de la request
 	"net/http"      // net/http: servidor HTTP estándar (Request/Response)
 	"time"          // time: duraciones y deadlines (timeouts en DB)
 
-	"go.mongodb.org/mongo-driver/bson"           // bson: documento estilo JSON para filtros/decodificación
-	"go.mongodb.org/mongo-driver/bson/primitive" // primitive: tipos especiales (ObjectID, etc.) de Mongo
-	"go.mongodb.org/mongo-driver/mongo"          // mongo: cliente/colección/métodos
+	"github.com/gastonduartem/Challenge-1/frontend/internal/access" // verificación del token del comprador
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf"   // token CSRF del form de cancelación
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models" // modelos de pedido/entrega
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store"  // repositorios de pedidos y entregas
+	"go.mongodb.org/mongo-driver/bson/primitive"                    // primitive: tipos especiales (ObjectID, etc.) de Mongo
 )
 
-// NewStatus construye un handler para GET /status/:id
+// StatusView: datos que consume order_status.tmpl.
+// Sirve para un pedido activo (en "orders"), uno entregado (en "deliveries")
+// y uno cancelado por el comprador (en "cancellations").
+type StatusView struct {
+	OrderID     string             // ID completo en hex (para armar links)
+	ShortID     string             // ID corto visual (#a1b2)
+	BuyerName   string             // nombre del comprador
+	Address     string             // dirección/iglú
+	Email       string             // email del comprador
+	Status      models.OrderStatus // nuevo/preparando/en_camino/entregado
+	Items       []models.Item      // ítems del pedido (snapshot)
+	Total       int                // total del pedido
+	CreatedAt   time.Time          // cuándo se hizo el pedido (cero si no se conoce)
+	DeliveredAt time.Time          // cuándo se entregó (sólo en estado terminal)
+	AutoRefresh bool               // true mientras el pedido siga activo (SSE, o <meta refresh> sin JS)
+	EventsURL   string             // stream SSE con los cambios del pedido (sólo si sigue activo)
+	Delivered   bool               // true si el pedido ya está en "deliveries"
+	Cancelled   bool               // true si el comprador lo canceló (está en "cancellations")
+	CancelledAt time.Time          // cuándo se canceló
+	Reason      string             // motivo de la cancelación
+	EditURL     string             // link privado de edición (sólo si el pedido sigue en "nuevo")
+	CancelURL   string             // action del form de cancelación (sólo si sigue en "nuevo")
+	CSRF        string             // token CSRF del form de cancelación
+}
+
+// statusViewFromOrder arma la vista de un pedido activo.
+// token es el del comprador: lo usamos para el link de edición. csrfToken va en el form de cancelación.
+func statusViewFromOrder(o models.Order, token, csrfToken string) StatusView {
+	v := StatusView{
+		OrderID:     o.ID.Hex(),
+		ShortID:     o.ShortID(),
+		BuyerName:   o.BuyerName,
+		Address:     o.Address,
+		Email:       o.Email,
+		Status:      o.Status,
+		Items:       o.Items,
+		Total:       o.Total,
+		CreatedAt:   o.CreatedAt,
+		AutoRefresh: true, // mientras esté activo, habilitamos auto-refresh en la vista
+		EventsURL:   statusEventsURL(o.ID, token),
+	}
+	if o.Editable() {
+		v.EditURL = editURL(o.ID, token)
+		v.CancelURL = cancelURL(o.ID, token)
+		v.CSRF = csrfToken
+	}
+	return v
+}
+
+// statusViewFromDelivery arma la vista de un pedido ya entregado (estado terminal).
+func statusViewFromDelivery(d models.Delivery) StatusView {
+	return StatusView{
+		OrderID:     d.OrderID.Hex(),
+		ShortID:     d.ShortID(),
+		BuyerName:   d.BuyerName,
+		Address:     d.Address,
+		Email:       d.Email,
+		Status:      models.StatusEntregado,
+		Items:       d.Items,
+		Total:       d.Total,
+		DeliveredAt: d.DeliveredAt,
+		Delivered:   true,
+	}
+}
+
+// statusViewFromCancellation arma la vista de un pedido cancelado (estado terminal).
+func statusViewFromCancellation(c models.Cancellation) StatusView {
+	return StatusView{
+		OrderID:     c.OrderID.Hex(),
+		ShortID:     c.ShortID(),
+		BuyerName:   c.BuyerName,
+		Address:     c.Address,
+		Email:       c.Email,
+		Status:      models.StatusCancelado,
+		Items:       c.Items,
+		Total:       c.Total,
+		CreatedAt:   c.CreatedAt,
+		Cancelled:   true,
+		CancelledAt: c.CancelledAt,
+		Reason:      c.Reason,
+	}
+}
+
+// NewStatus construye un handler para GET /status/:id?token=...
+// Sin el token del comprador respondemos 404 (no revelamos si el pedido existe).
 // Recibe:
-//   - colOrders: colección "orders" (pedidos activos)
-//   - colDeliveries: colección "deliveries" (pedidos entregados/histórico)
-//   - tmpl: plantilla HTML ya parseada (usada para renderizar el estado)
-func NewStatus(colOrders, colDeliveries *mongo.Collection, tmpl *template.Template) http.HandlerFunc {
+//   - orders: repositorio de "orders" (pedidos activos)
+//   - deliveries: repositorio de "deliveries" (pedidos entregados/histórico)
+//   - cancellations: repositorio de "cancellations" (pedidos cancelados por el comprador)
+//   - tmpl: plantillas ya parseadas (incluye "order_status.tmpl")
+func NewStatus(orders store.OrderStore, deliveries store.DeliveryStore, cancellations store.CancellationStore, tmpl *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) { // w: respuesta al cliente | r: request entrante
-		// Validación básica de ruta sin router: chequeamos que empiece con "/status/"
-		if len(r.URL.Path) < len("/status/") || r.URL.Path[:8] != "/status/" {
-			http.NotFound(w, r) // 404 si la ruta no cumple el patrón esperado
-			return
-		}
-		// Extraemos la parte de la URL que viene después de "/status/" → el id en hex
-		idHex := r.URL.Path[len("/status/"):]
+		// PathValue("id"): el segmento {id} del patrón GET /status/{id} (el router ya validó la forma de la ruta)
+		idHex := r.PathValue("id")
 		// Convertimos el id hex a ObjectID real de Mongo (24 chars hex → ObjectID binario)
 		oid, err := primitive.ObjectIDFromHex(idHex)
 		if err != nil {
@@ -34,45 +118,56 @@ func NewStatus(colOrders, colDeliveries *mongo.Collection, tmpl *template.Templa
 			return
 		}
 
+		token := r.URL.Query().Get("token") // token secreto que recibió el comprador en el checkout
+
 		// Contexto con timeout de 3s (si la DB tarda más, se cancela)
 		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 		defer cancel()
 
 		// Intentamos encontrar el pedido en la colección de "orders" (activos)
-		var order bson.M // bson.M = map[string]interface{} → documento genérico
-		if err := colOrders.FindOne(ctx, bson.M{"_id": oid}).Decode(&order); err == nil {
-			// Si existe como pedido activo, armamos el "view model" para el template
-			data := map[string]any{ // any (alias de interface{}) acepta cualquier tipo
-				"order_id":     idHex,           // mostramos el id en hex (amigable para la URL)
-				"status":       order["status"], // estado actual (nuevo/preparando/en_camino)
-				"items":        order["items"],  // array de items (vendrá como []interface{} con subdocs)
-				"total":        order["total"],  // total del pedido
-				"buyer_name":   order["buyer_name"],
-				"address":      order["address"],
-				"email":        order["email"],
-				"auto_refresh": true, // mientras esté activo, habilitamos auto-refresh en la vista
+		order, err := orders.FindByID(ctx, oid)
+		if err == nil {
+			if !access.Match(order.AccessTokenHash, token) {
+				http.Error(w, "pedido no encontrado", http.StatusNotFound)
+				return
 			}
-			_ = tmpl.Execute(w, data) // Renderizamos la plantilla directamente al ResponseWriter
-			return                    // Listo: ya respondimos
+			render(w, r, tmpl, "order_status.tmpl", http.StatusOK, statusViewFromOrder(order, token, csrf.Token(r)))
+			return
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
 		}
 
 		// Si NO está en "orders", buscamos en "deliveries" por order_id (lo guardamos al entregar)
-		var delivered bson.M
-		if err := colDeliveries.FindOne(ctx, bson.M{"order_id": oid}).Decode(&delivered); err != nil {
+		delivered, err := deliveries.FindByOrderID(ctx, oid)
+		if err == nil {
+			if !access.Match(delivered.AccessTokenHash, token) {
+				http.Error(w, "pedido no encontrado", http.StatusNotFound)
+				return
+			}
+			// Si está en deliveries, el estado es "entregado" y ya no auto-refrescamos
+			render(w, r, tmpl, "order_status.tmpl", http.StatusOK, statusViewFromDelivery(delivered))
+			return
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
+		}
+
+		// Último lugar: "cancellations" (el comprador lo canceló mientras estaba en "nuevo")
+		cancelled, err := cancellations.FindByOrderID(ctx, oid)
+		if errors.Is(err, store.ErrNotFound) || (err == nil && !access.Match(cancelled.AccessTokenHash, token)) {
 			http.Error(w, "pedido no encontrado", http.StatusNotFound) // 404 si no existe en ningún lado
 			return
 		}
-		// Si está en deliveries, el estado es "entregado" y ya no auto-refrescamos
-		data := map[string]any{
-			"order_id":     idHex,
-			"status":       "entregado",
-			"items":        delivered["items"],
-			"total":        delivered["total"],
-			"buyer_name":   delivered["buyer_name"],
-			"address":      delivered["address"],
-			"email":        delivered["email"],
-			"auto_refresh": false,
+		if err != nil {
+			slog.ErrorContext(ctx, "error al buscar el pedido", "order_id", oid.Hex(), "err", err)
+			http.Error(w, "error al buscar el pedido", http.StatusInternalServerError)
+			return
 		}
-		_ = tmpl.Execute(w, data) // Render SSR final
+		render(w, r, tmpl, "order_status.tmpl", http.StatusOK, statusViewFromCancellation(cancelled))
 	}
 }
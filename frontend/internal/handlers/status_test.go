@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// loadTemplates parsea las plantillas reales de internal/templates.
+func loadTemplates(t testing.TB) *template.Template {
+	t.Helper()
+	tmpls, err := ParseTemplates("../templates")
+	if err != nil {
+		t.Fatalf("ParseTemplates: %v", err)
+	}
+	return tmpls
+}
+
+func TestOrderStatusTemplate(t *testing.T) {
+	tmpls := loadTemplates(t)
+	id := primitive.NewObjectID()
+	items := []models.Item{{ProductID: primitive.NewObjectID(), Name: "Sardinas", UnitPrice: 5000, Qty: 2}}
+	order := models.Order{ID: id, BuyerName: "Pingu", Address: "Iglú 7", Items: items, Total: 10000, Status: models.StatusNuevo, CreatedAt: time.Now()}
+
+	cases := []struct {
+		name   string
+		view   StatusView
+		status string
+		want   []string // además de ShortID, BuyerName y el estado
+	}{
+		{"activo", statusViewFromOrder(order, "tok", "csrf-tok"), "nuevo", []string{`action="/orders/` + id.Hex() + `/cancel?token=tok"`, `value="csrf-tok"`}},
+		{"entregado", statusViewFromDelivery(models.Delivery{OrderID: id, BuyerName: "Pingu", Items: items, Total: 10000, DeliveredAt: time.Now()}), "entregado", []string{"Pedido entregado"}},
+		{"cancelado", statusViewFromCancellation(models.NewCancellation(order, "me equivoqué", time.Now())), "cancelado", []string{"Pedido cancelado", "me equivoqué"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			render(rec, httptest.NewRequest(http.MethodGet, "/status/"+id.Hex(), nil), tmpls, "order_status.tmpl", http.StatusOK, tc.view)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("código = %d, quiero 200", rec.Code)
+			}
+			body := rec.Body.String()
+			want := append([]string{"Pedido #" + order.ShortID(), "Pingu", ">" + tc.status + "</span>"}, tc.want...)
+			for _, s := range want {
+				if !strings.Contains(body, s) {
+					t.Errorf("falta %q en el HTML", s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTemplateError(t *testing.T) {
+	// order_status.tmpl con un dato que no es StatusView: la plantilla falla al leer .ShortID
+	rec := httptest.NewRecorder()
+	render(rec, httptest.NewRequest(http.MethodGet, "/", nil), loadTemplates(t), "order_status.tmpl", http.StatusOK, 42)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("código = %d, quiero 500", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "<html") {
+		t.Error("se mandó HTML a medias")
+	}
+}
@@ -0,0 +1,55 @@
+// templates.go — parseo de las plantillas SSR con sus funciones auxiliares
+
+package handlers
+
+import (
+	"fmt"           // fmt: formatear números en las plantillas
+	"html/template" // html/template: motor SSR nativo, seguro ante inyección HTML
+	"path/filepath" // filepath.Join: ruta de cada plantilla dentro de dir
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/csrf" // campo oculto con el token CSRF
+	"go.mongodb.org/mongo-driver/bson/primitive"                  // tipos especiales de Mongo (ObjectID)
+)
+
+// TemplateNames: plantillas de internal/templates que usan los handlers.
+var TemplateNames = []string{
+	"home.tmpl",
+	"orders_board.tmpl",
+	"order_status.tmpl",
+	"edit.tmpl",
+	"order_confirmation.tmpl",
+	"cart.tmpl",
+	"product.tmpl",
+	"error.tmpl",
+}
+
+// templateFuncs: funciones que podemos usar dentro de las plantillas HTML.
+var templateFuncs = template.FuncMap{
+	// "hex": convierte un ObjectID en su representación hexadecimal (24 chars)
+	"hex": func(id primitive.ObjectID) string { return id.Hex() },
+
+	// "fmtNumber": formatea un número como string (para mostrar precios)
+	"fmtNumber": func(n int) string { return fmt.Sprintf("%d", n) },
+
+	// "last4": devuelve los últimos 4 caracteres del ObjectID (ID corto visual)
+	"last4": func(id primitive.ObjectID) string {
+		h := id.Hex()
+		if len(h) >= 4 {
+			return h[len(h)-4:]
+		}
+		return h
+	},
+
+	// "csrfField": campo oculto con el token CSRF ({{csrfField .CSRF}} dentro de cada form POST)
+	"csrfField": csrf.Field,
+}
+
+// ParseTemplates carga las TemplateNames de dir con templateFuncs.
+// main la llama con "internal/templates"; los tests, con "../templates".
+func ParseTemplates(dir string) (*template.Template, error) {
+	files := make([]string, len(TemplateNames))
+	for i, name := range TemplateNames {
+		files[i] = filepath.Join(dir, name)
+	}
+	return template.New("").Funcs(templateFuncs).ParseFiles(files...)
+}
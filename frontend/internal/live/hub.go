@@ -0,0 +1,103 @@
+// hub.go — fan-out de los cambios de "orders" hacia los clientes SSE
+// Un solo change stream (store.OrderFeed) para todo el proceso; cada conexión SSE
+// se suscribe al Hub y recibe una copia de cada cambio.
+
+package live
+
+import (
+	"context"  // cancelación del feed al apagar
+	"log/slog" // slog: cortes del change stream
+	"sync"     // sync.Mutex: suscripciones desde goroutines de distintas requests
+	"time"     // backoff entre reintentos
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/store" // OrderFeed / OrderChange
+)
+
+// subBuffer: cambios que puede tener pendientes un suscriptor antes de que lo demos de baja.
+const subBuffer = 32
+
+// Reintentos del feed: arranca en minBackoff y se duplica hasta maxBackoff.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Hub reparte los cambios de pedidos entre los suscriptores.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[chan store.OrderChange]struct{}
+}
+
+// NewHub crea un Hub sin suscriptores (los cambios llegan cuando se llama a Run).
+func NewHub() *Hub {
+	return &Hub{subs: map[chan store.OrderChange]struct{}{}}
+}
+
+// Subscribe devuelve un canal con los cambios a partir de ahora y la función para darse de baja.
+// Si el canal se cierra el suscriptor se perdió cambios (quedó atrasado o se cortó el feed):
+// tiene que volver a sincronizarse con la DB.
+func (h *Hub) Subscribe() (<-chan store.OrderChange, func()) {
+	ch := make(chan store.OrderChange, subBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok { // puede que ya lo hayamos dado de baja nosotros
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish manda c a cada suscriptor sin bloquear: al que tiene el buffer lleno lo da de baja.
+func (h *Hub) publish(c store.OrderChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- c:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// dropAll da de baja a todos (después de un corte del feed ya no sabemos qué se perdieron).
+func (h *Hub) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// Run consume feed y reparte cada cambio hasta que ctx se cancele.
+// Si el stream se corta reintenta con backoff exponencial (el feed retoma desde el
+// último cambio cuando puede, pero igual se da de baja a los suscriptores para que resincronicen).
+func (h *Hub) Run(ctx context.Context, feed store.OrderFeed) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := feed.Watch(ctx, h.publish)
+		if ctx.Err() != nil {
+			h.dropAll()
+			return
+		}
+		slog.Warn("se cortó el change stream de orders", "err", err, "retry_in", backoff.String())
+		h.dropAll()
+
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff // venía andando bien: reintento rápido
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
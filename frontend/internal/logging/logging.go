@@ -0,0 +1,80 @@
+// logging.go — logs estructurados (log/slog en JSON) con el id de cada request
+// El middleware le asigna un id a cada request (o respeta el X-Request-ID que venga de un
+// proxy), lo guarda en el context y lo devuelve en la respuesta. Cualquier log hecho con
+// slog.*Context(ctx, ...) durante la request lleva request_id sin pasarlo a mano.
+
+package logging
+
+import (
+	"context"      // el id viaja en el context de la request
+	"crypto/rand"  // ids aleatorios
+	"encoding/hex" // id en texto
+	"io"           // destino de los logs
+	"log/slog"     // logger estructurado
+)
+
+// HeaderRequestID: header de entrada (si viene de un proxy) y de salida con el id de la request.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLen: los ids que vienen de afuera más largos que esto se reemplazan.
+const maxRequestIDLen = 64
+
+type ctxKey struct{}
+
+// New crea un logger JSON sobre w que agrega request_id cuando el context lo tiene.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// contextHandler agrega el request_id del context a cada registro.
+type contextHandler struct{ slog.Handler }
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// WithRequestID devuelve ctx con el id de request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// RequestID devuelve el id de request de ctx ("" si no tiene).
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// newRequestID genera un id aleatorio de 16 bytes en hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "sin-id" // crypto/rand no falla en la práctica; el id es sólo para correlacionar logs
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID acepta ids de afuera cortos y con caracteres seguros (van a logs y headers).
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
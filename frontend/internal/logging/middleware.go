@@ -0,0 +1,42 @@
+// middleware.go — request id + access log de cada request
+
+package logging
+
+import (
+	"log/slog" // access log
+	"net/http" // middleware
+	"time"     // duración de la request
+)
+
+// quietPaths: las sondas de Docker / orquestador y el scrape de Prometheus van a Debug
+// (si no, tapan todo lo demás).
+var quietPaths = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// Middleware asigna el id de request (X-Request-ID), lo pone en el context y en la respuesta,
+// y al terminar registra método, path, status, duración y bytes escritos.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		start := time.Now()
+		rec := &Recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if quietPaths[r.URL.Path] {
+			level = slog.LevelDebug
+		}
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", rec.Bytes(),
+		)
+	})
+}
@@ -0,0 +1,50 @@
+// recorder.go — ResponseWriter que guarda el código y los bytes de la respuesta
+// Lo usan el access log (Middleware) y las métricas HTTP (metrics.Instrument).
+
+package logging
+
+import "net/http" // http.ResponseWriter / Flusher
+
+// Recorder envuelve un http.ResponseWriter y guarda el status y los bytes escritos.
+// Implementa Flush (los streams SSE lo necesitan) y Unwrap (http.ResponseController llega
+// al ResponseWriter original).
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *Recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK // Write sin WriteHeader = 200
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *Recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *Recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Status: código de la respuesta (200 si el handler no escribió nada).
+func (r *Recorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Bytes: bytes del body escritos hasta ahora.
+func (r *Recorder) Bytes() int64 { return r.bytes }
@@ -0,0 +1,52 @@
+// catalog.go — estado del cache del catálogo (hits, misses, productos en memoria)
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus" // Collector: se lee el cache en cada scrape
+
+// CatalogStats: lo que el cache del catálogo expone en /metrics.
+type CatalogStats struct {
+	Ready    bool  // el cache refleja la DB y está respondiendo
+	Products int   // productos activos en memoria
+	Hits     int64 // lecturas respondidas desde memoria
+	Misses   int64 // lecturas que fueron a la DB
+}
+
+var (
+	catalogReady = prometheus.NewDesc(prometheus.BuildFQName(namespace, "catalog_cache", "ready"),
+		"1 si el cache del catálogo está al día y respondiendo, 0 si las lecturas van a la DB.", nil, nil)
+	catalogProducts = prometheus.NewDesc(prometheus.BuildFQName(namespace, "catalog_cache", "products"),
+		"Productos activos en el cache del catálogo.", nil, nil)
+	catalogHits = prometheus.NewDesc(prometheus.BuildFQName(namespace, "catalog_cache", "hits_total"),
+		"Lecturas del catálogo respondidas desde memoria.", nil, nil)
+	catalogMisses = prometheus.NewDesc(prometheus.BuildFQName(namespace, "catalog_cache", "misses_total"),
+		"Lecturas del catálogo que fueron a la DB.", nil, nil)
+)
+
+// RegisterCatalog publica en /metrics el estado del cache que devuelve stats (una lectura por
+// scrape). Se llama una sola vez, al armar el cache.
+func RegisterCatalog(stats func() CatalogStats) {
+	registry.MustRegister(catalogCollector(stats))
+}
+
+// catalogCollector: prometheus.Collector sobre la función de stats del cache.
+type catalogCollector func() CatalogStats
+
+func (c catalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- catalogReady
+	ch <- catalogProducts
+	ch <- catalogHits
+	ch <- catalogMisses
+}
+
+func (c catalogCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c()
+	ready := 0.0
+	if s.Ready {
+		ready = 1
+	}
+	ch <- prometheus.MustNewConstMetric(catalogReady, prometheus.GaugeValue, ready)
+	ch <- prometheus.MustNewConstMetric(catalogProducts, prometheus.GaugeValue, float64(s.Products))
+	ch <- prometheus.MustNewConstMetric(catalogHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(catalogMisses, prometheus.CounterValue, float64(s.Misses))
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterCatalog(t *testing.T) {
+	RegisterCatalog(func() CatalogStats { return CatalogStats{Ready: true, Products: 12, Hits: 40, Misses: 3} })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		"penguin_store_catalog_cache_ready 1",
+		"penguin_store_catalog_cache_products 12",
+		"penguin_store_catalog_cache_hits_total 40",
+		"penguin_store_catalog_cache_misses_total 3",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("falta %q en /metrics", want)
+		}
+	}
+}
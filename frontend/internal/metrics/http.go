@@ -0,0 +1,25 @@
+// http.go — middleware de métricas HTTP (cantidad y duración de requests por ruta)
+
+package metrics
+
+import (
+	"net/http" // middleware
+	"strconv"  // código de respuesta como label
+	"time"     // duración de la request
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/logging" // Recorder: código de la respuesta
+)
+
+// Instrument envuelve next y cuenta cada request con su duración bajo el label route.
+// route tiene que ser el patrón con el que se registró el handler ("/products/", no el path
+// pedido): así los ids de las URLs no generan una serie nueva por producto o pedido.
+func Instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &logging.Recorder{ResponseWriter: w} // mismo wrapper que el access log (Flush, Unwrap)
+		next.ServeHTTP(sw, r)
+
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(sw.Status())).Inc()
+	})
+}
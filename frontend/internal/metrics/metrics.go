@@ -0,0 +1,108 @@
+// metrics.go — métricas de Prometheus de la tienda (GET /metrics)
+// Un registry propio (no el global de client_golang) con las métricas del proceso y de Go,
+// más las de la tienda: requests HTTP por ruta, latencia de Mongo por colección, resultados
+// del checkout, valor de los pedidos, errores de plantilla y estado del cache del catálogo.
+
+package metrics
+
+import (
+	"net/http" // http.Handler de /metrics
+
+	"github.com/prometheus/client_golang/prometheus"            // tipos de métricas
+	"github.com/prometheus/client_golang/prometheus/collectors" // métricas de Go y del proceso
+	"github.com/prometheus/client_golang/prometheus/promauto"   // alta + registro en un paso
+	"github.com/prometheus/client_golang/prometheus/promhttp"   // exposición en formato texto
+)
+
+// namespace: prefijo de todas las métricas de la tienda (penguin_store_...).
+const namespace = "penguin_store"
+
+// Resultados del checkout (label "outcome" de penguin_store_checkouts_total).
+const (
+	CheckoutSuccess    = "success"          // pedido creado
+	CheckoutValidation = "validation_error" // datos del comprador o líneas inválidas (400)
+	CheckoutStock      = "stock_error"      // no alcanzó el stock de alguna línea (409)
+	CheckoutError      = "error"            // falla del servidor (500)
+)
+
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requests HTTP atendidas, por ruta registrada, método y código de respuesta.",
+	}, []string{"route", "method", "code"})
+
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duración de las requests HTTP por ruta registrada y método (los streams SSE miden la conexión entera).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	mongoDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "Duración de los comandos a MongoDB por colección y comando (find, insert, update, ...).",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "command"})
+
+	mongoErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_errors_total",
+		Help:      "Comandos a MongoDB que fallaron, por colección y comando.",
+	}, []string{"collection", "command"})
+
+	checkouts = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Intentos de checkout por resultado (success, validation_error, stock_error, error).",
+	}, []string{"outcome"})
+
+	orderValue = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_value_guaranies_total",
+		Help:      "Suma de los totales (en guaraníes) de los pedidos creados.",
+	})
+
+	templateErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "template_render_errors_total",
+		Help:      "Errores al ejecutar plantillas, por plantilla.",
+	}, []string{"template"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	// Los resultados del checkout arrancan en 0 (si no, no aparecen hasta que pasen la primera vez)
+	for _, outcome := range []string{CheckoutSuccess, CheckoutValidation, CheckoutStock, CheckoutError} {
+		checkouts.WithLabelValues(outcome)
+	}
+}
+
+// Handler sirve las métricas en el formato de texto de Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// Checkout cuenta un intento de checkout con el resultado outcome (una de las constantes Checkout*).
+func Checkout(outcome string) {
+	checkouts.WithLabelValues(outcome).Inc()
+}
+
+// OrderPlaced cuenta un checkout exitoso y suma total al valor de los pedidos.
+func OrderPlaced(total int) {
+	checkouts.WithLabelValues(CheckoutSuccess).Inc()
+	orderValue.Add(float64(total))
+}
+
+// TemplateError cuenta un error al ejecutar la plantilla name.
+func TemplateError(name string) {
+	templateErrors.WithLabelValues(name).Inc()
+}
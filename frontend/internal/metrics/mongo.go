@@ -0,0 +1,58 @@
+// mongo.go — latencia de los comandos a MongoDB (CommandMonitor del driver)
+
+package metrics
+
+import (
+	"context" // firma de los callbacks del monitor
+	"sync"    // sync.Map: comandos en vuelo
+
+	"go.mongodb.org/mongo-driver/bson"  // bson.Raw: el comando enviado
+	"go.mongodb.org/mongo-driver/event" // eventos de comandos
+)
+
+// noCollection: label de los comandos que no son sobre una colección (commitTransaction, ping, ...).
+const noCollection = "none"
+
+// MongoMonitor devuelve un monitor de comandos (options.Client().SetMonitor) que mide cada
+// comando por colección y nombre. El evento de fin no trae la colección: la anotamos al
+// empezar, por RequestID.
+func MongoMonitor() *event.CommandMonitor {
+	var inFlight sync.Map // RequestID → colección
+	finished := func(requestID int64) string {
+		col, ok := inFlight.LoadAndDelete(requestID)
+		if !ok {
+			return noCollection
+		}
+		return col.(string)
+	}
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			inFlight.Store(e.RequestID, commandCollection(e.Command))
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			col := finished(e.RequestID)
+			mongoDuration.WithLabelValues(col, e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			col := finished(e.RequestID)
+			mongoDuration.WithLabelValues(col, e.CommandName).Observe(e.Duration.Seconds())
+			mongoErrors.WithLabelValues(col, e.CommandName).Inc()
+		},
+	}
+}
+
+// commandCollection saca la colección del comando: es el valor del primer campo
+// ({find: "products", ...}) salvo en getMore, que la lleva en "collection".
+func commandCollection(cmd bson.Raw) string {
+	first, err := cmd.IndexErr(0)
+	if err != nil {
+		return noCollection
+	}
+	if col, ok := first.Value().StringValueOK(); ok {
+		return col
+	}
+	if col, ok := cmd.Lookup("collection").StringValueOK(); ok {
+		return col
+	}
+	return noCollection
+}
@@ -8,12 +8,53 @@ package models
 // En Go, los archivos se agrupan por paquetes (como módulos o namespaces).
 
 // Importaciones
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time" // time.Time: fechas (created_at) → BSON Date
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Importamos el paquete `primitive` del driver oficial de MongoDB para Go.
 // Este paquete provee tipos especiales compatibles con MongoDB.
 // Por ejemplo: `primitive.ObjectID` representa el tipo `_id` de MongoDB (el identificador único).
 
+// TIPO: OrderStatus — estado de un pedido.
+// Los tres primeros son los que admite el enum de Order.js (pedidos activos);
+// "entregado" sólo existe en "deliveries" (status_at_delivery) y
+// "cancelado" sólo en "cancellations" (status_at_cancellation).
+type OrderStatus string
+
+const (
+	StatusNuevo      OrderStatus = "nuevo"      // recién creado por el comprador (todavía editable)
+	StatusPreparando OrderStatus = "preparando" // Paula lo está armando
+	StatusEnCamino   OrderStatus = "en_camino"  // ya salió hacia el iglú
+	StatusEntregado  OrderStatus = "entregado"  // terminal: el pedido pasó a "deliveries"
+	StatusCancelado  OrderStatus = "cancelado"  // terminal: el comprador lo canceló (pasó a "cancellations")
+)
+
+// Valid indica si s es uno de los estados conocidos.
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case StatusNuevo, StatusPreparando, StatusEnCamino, StatusEntregado, StatusCancelado:
+		return true
+	}
+	return false
+}
+
+// Active indica si el estado corresponde a un pedido que sigue en "orders".
+func (s OrderStatus) Active() bool {
+	return s == StatusNuevo || s == StatusPreparando || s == StatusEnCamino
+}
+
+// shortID devuelve los últimos 4 caracteres del ObjectID en hex (ID corto visual "#a1b2").
+func shortID(id primitive.ObjectID) string {
+	h := id.Hex()
+	if len(h) > 4 {
+		return h[len(h)-4:]
+	}
+	return h
+}
+
 // STRUCT: Product — representa un documento en la colección "products"
 type Product struct {
 	ID primitive.ObjectID `bson:"_id"`
@@ -35,6 +76,46 @@ type Product struct {
 	ImagePath string `bson:"image_path"`
 	// Ruta pública a la imagen (por ejemplo: "/uploads/pescado.png").
 	// MongoDB la guarda como texto simple.
+
+	Active bool `bson:"is_active"`
+	// Si el admin lo tiene a la venta. La home sólo lista activos y el checkout rechaza los inactivos.
+
+	Stock int `bson:"stock"`
+	// Unidades en depósito (las carga el admin); se descuentan recién al entregar.
+
+	Reserved int `bson:"reserved"`
+	// Unidades reservadas por pedidos todavía no entregados (las reserva el checkout).
+
+	Category string `bson:"category"`
+	// Slug de la categoría (uno de Categories); vacío en productos cargados antes de las categorías.
+
+	UpdatedAt time.Time `bson:"updated_at"`
+	// Última modificación en el admin (hook pre-save de Product.js).
+}
+
+// LowStockThreshold: con esta cantidad disponible o menos mostramos "últimas N unidades".
+const LowStockThreshold = 5
+
+// Available devuelve las unidades que se pueden vender: stock - reserved (nunca negativo).
+func (p Product) Available() int {
+	if n := p.Stock - p.Reserved; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// SoldOut indica si no queda ninguna unidad disponible.
+func (p Product) SoldOut() bool { return p.Available() == 0 }
+
+// FewLeft indica si quedan pocas unidades (entre 1 y LowStockThreshold).
+func (p Product) FewLeft() bool {
+	n := p.Available()
+	return n > 0 && n <= LowStockThreshold
+}
+
+// OrderLimit devuelve el tope de cantidad para un pedido: lo disponible, sin pasar de max.
+func (p Product) OrderLimit(max int) int {
+	return min(p.Available(), max)
 }
 
 // STRUCT: Item — representa un ítem dentro de un pedido
@@ -55,6 +136,33 @@ type Item struct {
 
 	Subtotal int `bson:"subtotal"`
 	// qty * unit_price → total por este ítem.
+
+	Category string `bson:"category,omitempty"`
+	// Categoría del producto al momento de la compra (snapshot, para reportes por categoría).
+}
+
+// STRUCT: Category — categoría del catálogo (lista fija, igual que backend/src/models/categories.js)
+type Category struct {
+	Slug string // lo que se guarda en products.category y va en /category/<slug>
+	Name string // nombre para mostrar
+}
+
+// Categories: categorías del catálogo, en el orden en que se muestran.
+var Categories = []Category{
+	{Slug: "pescados", Name: "Pescados"},
+	{Slug: "krill", Name: "Krill"},
+	{Slug: "hielo", Name: "Bloques de hielo"},
+	{Slug: "accesorios", Name: "Accesorios"},
+}
+
+// CategoryBySlug busca una categoría por slug.
+func CategoryBySlug(slug string) (Category, bool) {
+	for _, c := range Categories {
+		if c.Slug == slug {
+			return c, true
+		}
+	}
+	return Category{}, false
 }
 
 // STRUCT: Order — representa un pedido completo
@@ -68,11 +176,14 @@ type Order struct {
 	Address string `bson:"address"`
 	// Dirección o iglú donde se entregará el pedido.
 
+	IglooSector string `bson:"igloo_sector"`
+	// Sector opcional del iglú (para agrupar zonas). Vacío por defecto.
+
 	Email string `bson:"email"`
 	// Correo del comprador.
 
-	Status string `bson:"status"`
-	// Estado actual del pedido: "nuevo", "preparando", "en_camino", etc.
+	Status OrderStatus `bson:"status"`
+	// Estado actual del pedido: "nuevo", "preparando" o "en_camino" (ver constantes Status*).
 
 	Items []Item `bson:"items"`
 	// Slice (lista dinámica en Go) de `Item`.
@@ -81,4 +192,149 @@ type Order struct {
 
 	Total int `bson:"total"`
 	// Total general del pedido (suma de todos los subtotales).
+
+	CreatedAt time.Time `bson:"created_at"`
+	// Fecha de creación del pedido (time.Time → BSON Date).
+
+	AccessTokenHash string `bson:"access_token_hash"`
+	// SHA-256 del token secreto que recibe el comprador (ver internal/access).
+	// Sin el token no se puede ver ni editar el pedido desde la tienda.
+}
+
+// ShortID devuelve el ID corto del pedido (últimos 4 chars del _id), el que ve el comprador.
+func (o Order) ShortID() string { return shortID(o.ID) }
+
+// Editable indica si el comprador todavía puede modificar el pedido.
+func (o Order) Editable() bool { return o.Status == StatusNuevo }
+
+// STRUCT: StockDelta — cuánto stock se descontó de un producto al entregar
+type StockDelta struct {
+	ProductID primitive.ObjectID `bson:"product_id"`
+	Qty       int                `bson:"qty"`
+}
+
+// STRUCT: Delivery — representa un pedido entregado en la colección "deliveries"
+// (snapshot inmutable que el admin guarda al marcar el pedido como entregado, ver Delivery.js)
+type Delivery struct {
+	ID primitive.ObjectID `bson:"_id"`
+	// ID del documento de entrega.
+
+	OrderID primitive.ObjectID `bson:"order_id"`
+	// ID del pedido original (lo usamos para buscar el estado desde /status/).
+
+	Items []Item `bson:"items"`
+	// Snapshot de los ítems entregados.
+
+	Total int `bson:"total"`
+	// Total del pedido al momento de la entrega.
+
+	BuyerName   string `bson:"buyer_name"`
+	Address     string `bson:"address"`
+	IglooSector string `bson:"igloo_sector"`
+	Email       string `bson:"email"`
+	// Datos del comprador (snapshot).
+
+	DeliveredAt time.Time `bson:"delivered_at"`
+	// Fecha y hora exacta en la que se marcó como entregado.
+
+	StatusAtDelivery OrderStatus `bson:"status_at_delivery"`
+	// Estado fijo al momento de la entrega (siempre "entregado").
+
+	StockDelta []StockDelta `bson:"stock_delta"`
+	// Qué stock se descontó de cada producto (auditoría).
+
+	AccessTokenHash string `bson:"access_token_hash"`
+	// Copiado del pedido al entregar: el comprador sigue viendo su /status/ con el mismo token.
+
+	Day   string `bson:"day"`   // "2025-11-07"
+	Month string `bson:"month"` // "2025-11"
+	Year  int    `bson:"year"`  // 2025
+	// Campos de fecha desnormalizados para agrupar sin cálculos costosos.
+}
+
+// ShortID devuelve el ID corto del pedido entregado (mismo formato que Order.ShortID).
+func (d Delivery) ShortID() string { return shortID(d.OrderID) }
+
+// STRUCT: Cancellation — pedido cancelado por el comprador, en la colección "cancellations"
+// (mismo patrón de snapshot que "deliveries": se copia el pedido y se borra de "orders")
+type Cancellation struct {
+	ID primitive.ObjectID `bson:"_id"`
+	// ID del documento de cancelación.
+
+	OrderID primitive.ObjectID `bson:"order_id"`
+	// ID del pedido original.
+
+	Items []Item `bson:"items"`
+	Total int    `bson:"total"`
+	// Snapshot de los ítems y el total al momento de cancelar.
+
+	BuyerName   string `bson:"buyer_name"`
+	Address     string `bson:"address"`
+	IglooSector string `bson:"igloo_sector"`
+	Email       string `bson:"email"`
+	// Datos del comprador (snapshot).
+
+	Reason string `bson:"reason"`
+	// Motivo que escribió el comprador.
+
+	CreatedAt   time.Time `bson:"created_at"`   // cuándo se había hecho el pedido
+	CancelledAt time.Time `bson:"cancelled_at"` // cuándo se canceló
+
+	StatusAtCancellation OrderStatus `bson:"status_at_cancellation"`
+	// Estado fijo (siempre "cancelado").
+
+	AccessTokenHash string `bson:"access_token_hash"`
+	// Copiado del pedido: el comprador sigue viendo su /status/ con el mismo token.
+
+	Day   string `bson:"day"`   // "2025-11-07"
+	Month string `bson:"month"` // "2025-11"
+	Year  int    `bson:"year"`  // 2025
+	// Campos de fecha desnormalizados (igual que en Delivery).
+}
+
+// ShortID devuelve el ID corto del pedido cancelado (mismo formato que Order.ShortID).
+func (c Cancellation) ShortID() string { return shortID(c.OrderID) }
+
+// NewCancellation arma el snapshot de cancelación de o con el motivo y la fecha at.
+func NewCancellation(o Order, reason string, at time.Time) Cancellation {
+	return Cancellation{
+		ID:                   primitive.NewObjectID(),
+		OrderID:              o.ID,
+		Items:                o.Items,
+		Total:                o.Total,
+		BuyerName:            o.BuyerName,
+		Address:              o.Address,
+		IglooSector:          o.IglooSector,
+		Email:                o.Email,
+		Reason:               reason,
+		CreatedAt:            o.CreatedAt,
+		CancelledAt:          at,
+		StatusAtCancellation: StatusCancelado,
+		AccessTokenHash:      o.AccessTokenHash,
+		Day:                  at.Format("2006-01-02"),
+		Month:                at.Format("2006-01"),
+		Year:                 at.Year(),
+	}
+}
+
+// STRUCT: Cart — carrito de compras de una sesión anónima (colección "carts")
+// Sólo guarda cantidades: nombre y precio se leen del catálogo al mostrarlo o al hacer checkout.
+type Cart struct {
+	SessionID string `bson:"_id"`
+	// Id de sesión (el de la cookie firmada); un carrito por sesión.
+
+	Items map[string]int `bson:"items"`
+	// Cantidad por producto (ID hex → qty), igual que los campos qty_<id> del form de la home.
+
+	UpdatedAt time.Time `bson:"updated_at"`
+	// Último cambio. El índice TTL de "carts" borra los carritos abandonados a partir de acá.
+}
+
+// Count devuelve la cantidad total de unidades en el carrito.
+func (c Cart) Count() int {
+	n := 0
+	for _, q := range c.Items {
+		n += q
+	}
+	return n
 }
@@ -0,0 +1,63 @@
+// validate.go — Reglas de integridad de los modelos
+// Espejan las validaciones de los esquemas de Mongoose (Order.js / Delivery.js),
+// así la tienda no inserta documentos que el admin no podría leer.
+
+package models
+
+import (
+	"errors" // errors.New / errors.Join: acumular todos los problemas encontrados
+	"fmt"    // fmt.Errorf: mensajes con el índice del ítem
+)
+
+// Validate revisa un ítem: producto, nombre, cantidad >= 1, precio >= 0 y subtotal coherente.
+func (it Item) Validate() error {
+	var errs []error
+	if it.ProductID.IsZero() {
+		errs = append(errs, errors.New("falta product_id"))
+	}
+	if it.Name == "" {
+		errs = append(errs, errors.New("falta el nombre del producto"))
+	}
+	if it.Qty < 1 {
+		errs = append(errs, errors.New("la cantidad debe ser al menos 1"))
+	}
+	if it.UnitPrice < 0 {
+		errs = append(errs, errors.New("el precio unitario no puede ser negativo"))
+	}
+	if it.Subtotal != it.Qty*it.UnitPrice {
+		errs = append(errs, errors.New("el subtotal no coincide con cantidad × precio"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate revisa un pedido activo: datos del comprador, estado, ítems y total.
+// Devuelve nil si está todo bien, o un error que junta todos los problemas.
+func (o Order) Validate() error {
+	var errs []error
+	if o.BuyerName == "" {
+		errs = append(errs, errors.New("falta el nombre del comprador"))
+	}
+	if o.Address == "" {
+		errs = append(errs, errors.New("falta la dirección"))
+	}
+	if o.Email == "" {
+		errs = append(errs, errors.New("falta el email"))
+	}
+	if !o.Status.Active() {
+		errs = append(errs, fmt.Errorf("estado inválido para un pedido activo: %q", o.Status))
+	}
+	if len(o.Items) == 0 {
+		errs = append(errs, errors.New("el pedido no tiene ítems"))
+	}
+	sum := 0
+	for i, it := range o.Items {
+		if err := it.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("ítem %d: %w", i+1, err))
+		}
+		sum += it.Subtotal
+	}
+	if o.Total != sum {
+		errs = append(errs, errors.New("el total no coincide con la suma de subtotales"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,81 @@
+// session.go — Id de sesión anónima en una cookie firmada (carrito de compras)
+// El id es aleatorio y viaja firmado con HMAC-SHA256: sin el secreto del servidor
+// no se puede fabricar una cookie válida ni adivinar el carrito de otro comprador.
+
+package session
+
+import (
+	"crypto/hmac"     // firma del id
+	"crypto/rand"     // ids aleatorios
+	"crypto/sha256"   // HMAC-SHA256
+	"encoding/base64" // id y firma aptos para cookie
+	"net/http"        // cookies
+	"strings"         // strings.Cut: separar id y firma
+	"time"            // vencimiento de la cookie
+)
+
+// CookieName: nombre de la cookie con el id de sesión.
+const CookieName = "pingu_session"
+
+// MaxAge: cuánto vive la cookie (igual que el TTL de los carritos en Mongo).
+const MaxAge = 30 * 24 * time.Hour
+
+// idBytes: 16 bytes aleatorios → 128 bits, suficiente para un id que además va firmado.
+const idBytes = 16
+
+// Manager firma y verifica la cookie de sesión con un secreto del servidor.
+type Manager struct {
+	secret []byte
+}
+
+// NewManager crea un Manager con el secreto dado (SESSION_SECRET).
+func NewManager(secret string) *Manager {
+	return &Manager{secret: []byte(secret)}
+}
+
+// sign devuelve la firma base64url del id.
+func (m *Manager) sign(id string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Peek devuelve el id de sesión de la request si la cookie existe y la firma es válida.
+// No crea sesiones: sirve para leer el carrito sin dejar cookies a quien sólo mira.
+func (m *Manager) Peek(r *http.Request) (string, bool) {
+	c, err := r.Cookie(CookieName)
+	if err != nil {
+		return "", false
+	}
+	id, sig, ok := strings.Cut(c.Value, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	// hmac.Equal compara en tiempo constante
+	if !hmac.Equal([]byte(sig), []byte(m.sign(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// ID devuelve el id de sesión de la request; si no hay uno válido genera uno nuevo
+// y lo deja en la respuesta (Set-Cookie). Hay que llamarlo antes de escribir el body.
+func (m *Manager) ID(w http.ResponseWriter, r *http.Request) (string, error) {
+	if id, ok := m.Peek(r); ok {
+		return id, nil
+	}
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id + "." + m.sign(id),
+		Path:     "/",
+		MaxAge:   int(MaxAge / time.Second),
+		HttpOnly: true,                 // JS no la lee
+		SameSite: http.SameSiteLaxMode, // no viaja en POSTs de otros sitios
+	})
+	return id, nil
+}
@@ -0,0 +1,431 @@
+// memory.go — Implementación en memoria de los stores
+// Pensada para tests de handlers con httptest (sin replica set corriendo).
+// Replica las reglas de la versión Mongo: reserva de stock atómica y ErrNotFound.
+
+package store
+
+import (
+	"context" // firma de las interfaces (no se usa para cancelar nada en memoria)
+	"sort"    // orden estable de resultados (los maps no tienen orden)
+	"sync"    // sync.Mutex: los handlers corren en goroutines concurrentes
+	"time"    // created_at del pedido
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Memory guarda productos, pedidos y entregas en maps protegidos por un mutex.
+type Memory struct {
+	mu         sync.Mutex
+	products   map[primitive.ObjectID]*models.Product // con su inventario (Stock, Reserved, Active)
+	orders     map[primitive.ObjectID]models.Order
+	deliveries map[primitive.ObjectID]models.Delivery // clave: order_id
+
+	cancellations map[primitive.ObjectID]models.Cancellation // clave: order_id
+	carts         map[string]models.Cart                     // clave: id de sesión
+
+	watchers        map[int]func(OrderChange)   // OrderFeed.Watch activos (clave: id de registro)
+	productWatchers map[int]func(ProductChange) // ProductFeed.Watch activos
+	nextW           int
+}
+
+// NewMemory crea un almacenamiento en memoria vacío.
+func NewMemory() *Memory {
+	return &Memory{
+		products:   map[primitive.ObjectID]*models.Product{},
+		orders:     map[primitive.ObjectID]models.Order{},
+		deliveries: map[primitive.ObjectID]models.Delivery{},
+
+		cancellations: map[primitive.ObjectID]models.Cancellation{},
+		carts:         map[string]models.Cart{},
+
+		watchers:        map[int]func(OrderChange){},
+		productWatchers: map[int]func(ProductChange){},
+	}
+}
+
+// Stores devuelve los repositorios respaldados por esta memoria.
+func (m *Memory) Stores() Stores {
+	return Stores{
+		Products:      memProducts{m},
+		Orders:        memOrders{m},
+		Deliveries:    memDeliveries{m},
+		Cancellations: memCancellations{m},
+		Carts:         memCarts{m},
+		OrderFeed:     memOrderFeed{m},
+		ProductFeed:   memProductFeed{m},
+	}
+}
+
+// notify avisa a los Watch activos que cambió el pedido id (o es nil si salió de "orders").
+// Se llama con m.mu tomado.
+func (m *Memory) notify(id primitive.ObjectID, o *models.Order) {
+	for _, fn := range m.watchers {
+		fn(OrderChange{OrderID: id, Order: o})
+	}
+}
+
+// notifyProducts avisa a los ProductFeed.Watch activos que cambiaron (stock, reservas, datos)
+// los productos ids. Se llama con m.mu tomado.
+func (m *Memory) notifyProducts(ids ...primitive.ObjectID) {
+	for _, id := range ids {
+		p, ok := m.products[id]
+		if !ok {
+			continue
+		}
+		v := *p
+		for _, fn := range m.productWatchers {
+			fn(ProductChange{ProductID: id, Product: &v})
+		}
+	}
+}
+
+// PutProduct agrega (o reemplaza) un producto tal cual viene, inventario incluido
+// (p.Stock, p.Reserved, p.Active). Si p.ID es cero se genera uno nuevo; devuelve el ID final.
+func (m *Memory) PutProduct(p models.Product) primitive.ObjectID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p.ID.IsZero() {
+		p.ID = primitive.NewObjectID()
+	}
+	m.products[p.ID] = &p
+	m.notifyProducts(p.ID)
+	return p.ID
+}
+
+// Reserved devuelve las unidades reservadas de un producto (para asserts en tests).
+func (m *Memory) Reserved(id primitive.ObjectID) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.products[id]; ok {
+		return p.Reserved
+	}
+	return 0
+}
+
+// PutDelivery agrega una entrega (como si el admin hubiera marcado el pedido como entregado).
+func (m *Memory) PutDelivery(d models.Delivery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d.ID.IsZero() {
+		d.ID = primitive.NewObjectID()
+	}
+	m.deliveries[d.OrderID] = d
+}
+
+// ====== PRODUCTS ======
+
+type memProducts struct{ m *Memory }
+
+func (s memProducts) ListActive(_ context.Context) ([]models.Product, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	var out []models.Product
+	for _, p := range s.m.products {
+		if p.Active {
+			out = append(out, *p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID.Hex() < out[j].ID.Hex() })
+	return out, nil
+}
+
+// Search arma la lista con el inventario actual y aplica SearchProducts.
+func (s memProducts) Search(_ context.Context, q CatalogQuery) (CatalogPage, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	all := make([]models.Product, 0, len(s.m.products))
+	for _, p := range s.m.products {
+		all = append(all, *p)
+	}
+	return SearchProducts(all, q), nil
+}
+
+func (s memProducts) FindByID(_ context.Context, id primitive.ObjectID) (models.Product, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	p, ok := s.m.products[id]
+	if !ok {
+		return models.Product{}, ErrNotFound
+	}
+	return *p, nil
+}
+
+func (s memProducts) FindByIDs(_ context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Product, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	out := make(map[primitive.ObjectID]models.Product, len(ids))
+	for _, id := range ids {
+		if p, ok := s.m.products[id]; ok {
+			out[id] = *p
+		}
+	}
+	return out, nil
+}
+
+// ====== ORDERS ======
+
+type memOrders struct{ m *Memory }
+
+func (s memOrders) Create(_ context.Context, order *models.Order) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	// Primero verificamos todas las líneas (equivale al rollback de la transacción en Mongo).
+	// Sumamos por producto: en Mongo cada $inc condicional ve las reservas de las líneas
+	// anteriores, así que dos líneas del mismo producto no pueden pasarse del stock juntas.
+	deltas := itemDeltas(nil, order.Items)
+	var stockErr StockError
+	for _, d := range deltas {
+		p, ok := s.m.products[d.ProductID]
+		available := 0
+		if ok && p.Active {
+			available = p.Available()
+		}
+		if available < d.New {
+			stockErr.Shortages = append(stockErr.Shortages, Shortage{
+				ProductID: d.ProductID,
+				Name:      d.Name,
+				Requested: d.New,
+				Available: available,
+			})
+		}
+	}
+	if len(stockErr.Shortages) > 0 {
+		return &stockErr
+	}
+
+	// Todas las líneas alcanzan: reservamos e insertamos
+	for _, d := range deltas {
+		s.m.products[d.ProductID].Reserved += d.New
+		s.m.notifyProducts(d.ProductID)
+	}
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+	s.m.orders[order.ID] = *order
+	created := *order
+	s.m.notify(order.ID, &created)
+	return nil
+}
+
+func (s memOrders) FindByID(_ context.Context, id primitive.ObjectID) (models.Order, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	o, ok := s.m.orders[id]
+	if !ok {
+		return models.Order{}, ErrNotFound
+	}
+	return o, nil
+}
+
+func (s memOrders) List(_ context.Context) ([]models.Order, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	out := make([]models.Order, 0, len(s.m.orders))
+	for _, o := range s.m.orders {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s memOrders) Edit(_ context.Context, order models.Order) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	current, ok := s.m.orders[order.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !current.Editable() {
+		return ErrNotEditable
+	}
+
+	// Primero verificamos que alcance el stock para todo lo que aumenta
+	deltas := itemDeltas(current.Items, order.Items)
+	var stockErr StockError
+	for _, d := range deltas {
+		if d.New <= d.Old {
+			continue
+		}
+		p, ok := s.m.products[d.ProductID]
+		available := 0
+		if ok && p.Active {
+			available = p.Available()
+		}
+		if available < d.New-d.Old {
+			stockErr.Shortages = append(stockErr.Shortages, Shortage{
+				ProductID: d.ProductID,
+				Name:      d.Name,
+				Requested: d.New,
+				Available: available + d.Old,
+			})
+		}
+	}
+	if len(stockErr.Shortages) > 0 {
+		return &stockErr
+	}
+
+	// Aplicamos reservas/liberaciones y guardamos
+	for _, d := range deltas {
+		if p, ok := s.m.products[d.ProductID]; ok {
+			p.Reserved += d.New - d.Old
+			if p.Reserved < 0 {
+				p.Reserved = 0
+			}
+			s.m.notifyProducts(d.ProductID)
+		}
+	}
+	current.BuyerName = order.BuyerName
+	current.Address = order.Address
+	current.IglooSector = order.IglooSector
+	current.Items = order.Items
+	current.Total = order.Total
+	s.m.orders[order.ID] = current
+	s.m.notify(order.ID, &current)
+	return nil
+}
+
+func (s memOrders) Cancel(_ context.Context, id primitive.ObjectID, reason string) (models.Cancellation, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	order, ok := s.m.orders[id]
+	if !ok {
+		return models.Cancellation{}, ErrNotFound
+	}
+	if !order.Editable() {
+		return models.Cancellation{}, ErrNotEditable
+	}
+	for _, it := range order.Items {
+		if p, ok := s.m.products[it.ProductID]; ok {
+			p.Reserved -= it.Qty
+			if p.Reserved < 0 {
+				p.Reserved = 0
+			}
+			s.m.notifyProducts(it.ProductID)
+		}
+	}
+	c := models.NewCancellation(order, reason, time.Now())
+	s.m.cancellations[id] = c
+	delete(s.m.orders, id)
+	s.m.notify(id, nil)
+	return c, nil
+}
+
+// ====== DELIVERIES ======
+
+type memDeliveries struct{ m *Memory }
+
+func (s memDeliveries) FindByOrderID(_ context.Context, orderID primitive.ObjectID) (models.Delivery, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	d, ok := s.m.deliveries[orderID]
+	if !ok {
+		return models.Delivery{}, ErrNotFound
+	}
+	return d, nil
+}
+
+// ====== CANCELLATIONS ======
+
+type memCancellations struct{ m *Memory }
+
+func (s memCancellations) FindByOrderID(_ context.Context, orderID primitive.ObjectID) (models.Cancellation, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	c, ok := s.m.cancellations[orderID]
+	if !ok {
+		return models.Cancellation{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// ====== ORDER FEED ======
+
+type memOrderFeed struct{ m *Memory }
+
+// Watch registra fn y la deja activa hasta que ctx se cancele (en memoria el stream no se corta).
+func (f memOrderFeed) Watch(ctx context.Context, fn func(OrderChange)) error {
+	f.m.mu.Lock()
+	id := f.m.nextW
+	f.m.nextW++
+	f.m.watchers[id] = fn
+	f.m.mu.Unlock()
+
+	<-ctx.Done()
+
+	f.m.mu.Lock()
+	delete(f.m.watchers, id)
+	f.m.mu.Unlock()
+	return ctx.Err()
+}
+
+// ====== PRODUCT FEED ======
+
+type memProductFeed struct{ m *Memory }
+
+// Watch registra fn, avisa Opened (nunca Resumed: cada Watch empieza de cero) y la deja
+// activa hasta que ctx se cancele. Los cambios se avisan con el store bloqueado: fn no
+// puede volver a llamar al store (salvo en el aviso de Opened).
+func (f memProductFeed) Watch(ctx context.Context, fn func(ProductChange)) error {
+	f.m.mu.Lock()
+	id := f.m.nextW
+	f.m.nextW++
+	f.m.productWatchers[id] = fn
+	f.m.mu.Unlock()
+
+	fn(ProductChange{Opened: true})
+	<-ctx.Done()
+
+	f.m.mu.Lock()
+	delete(f.m.productWatchers, id)
+	f.m.mu.Unlock()
+	return ctx.Err()
+}
+
+// ====== CARTS ======
+
+type memCarts struct{ m *Memory }
+
+func (s memCarts) Get(_ context.Context, sessionID string) (models.Cart, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	c, ok := s.m.carts[sessionID]
+	if !ok {
+		return models.Cart{SessionID: sessionID, Items: map[string]int{}}, nil
+	}
+	// copiamos el map: el llamador no tiene que poder modificar el carrito guardado
+	items := make(map[string]int, len(c.Items))
+	for k, v := range c.Items {
+		items[k] = v
+	}
+	c.Items = items
+	return c, nil
+}
+
+func (s memCarts) SetQty(_ context.Context, sessionID string, productID primitive.ObjectID, qty int) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	c, ok := s.m.carts[sessionID]
+	if !ok {
+		c = models.Cart{SessionID: sessionID, Items: map[string]int{}}
+	}
+	if qty <= 0 {
+		delete(c.Items, productID.Hex())
+	} else {
+		c.Items[productID.Hex()] = qty
+	}
+	c.UpdatedAt = time.Now()
+	s.m.carts[sessionID] = c
+	return nil
+}
+
+func (s memCarts) Clear(_ context.Context, sessionID string) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	delete(s.m.carts, sessionID)
+	return nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+)
+
+func TestMemoryCreateSumsLinesPerProduct(t *testing.T) {
+	m := NewMemory()
+	pid := m.PutProduct(models.Product{Name: "Sardinas", Price: 5000, Stock: 3, Active: true})
+	line := func(qty int) models.Item {
+		return models.Item{ProductID: pid, Name: "Sardinas", Qty: qty, UnitPrice: 5000, Subtotal: 5000 * qty}
+	}
+
+	// Como en Mongo: la segunda línea ve la reserva de la primera
+	err := m.Stores().Orders.Create(context.Background(), &models.Order{Items: []models.Item{line(3), line(3)}})
+	var stockErr *StockError
+	if !errors.As(err, &stockErr) || len(stockErr.Shortages) != 1 || stockErr.Shortages[0].Requested != 6 {
+		t.Fatalf("3+3 con stock 3: err = %v, quiero un faltante de 6", err)
+	}
+	if got := m.Reserved(pid); got != 0 {
+		t.Fatalf("reservado tras el rechazo = %d, quiero 0", got)
+	}
+
+	if err := m.Stores().Orders.Create(context.Background(), &models.Order{Items: []models.Item{line(2), line(1)}}); err != nil {
+		t.Fatalf("2+1 con stock 3: %v", err)
+	}
+	if got := m.Reserved(pid); got != 3 {
+		t.Errorf("reservado = %d, quiero 3", got)
+	}
+}
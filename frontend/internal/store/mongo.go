@@ -0,0 +1,606 @@
+// mongo.go — Implementación de los stores sobre MongoDB
+
+package store
+
+import (
+	"context"  // context.Context: deadlines que vienen desde el handler
+	"errors"   // errors.Is / As: traducir mongo.ErrNoDocuments a ErrNotFound, códigos del servidor
+	"log/slog" // slog: fallas de Mongo con colección y operación
+	"time"     // time.Now: created_at del pedido
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"           // filtros/updates BSON
+	"go.mongodb.org/mongo-driver/bson/primitive" // ObjectID
+	"go.mongodb.org/mongo-driver/mongo"          // *mongo.Database / *mongo.Collection
+	"go.mongodb.org/mongo-driver/mongo/options"  // proyecciones
+)
+
+// CartTTL: los carritos sin cambios durante este tiempo los borra Mongo (índice TTL).
+const CartTTL = 30 * 24 * time.Hour
+
+// NewMongo arma los stores sobre las colecciones "products", "orders", "deliveries",
+// "cancellations" y "carts" de database. El OrderFeed usa change streams (necesita replica set).
+func NewMongo(database *mongo.Database) Stores {
+	products := database.Collection("products")
+	cancellations := database.Collection("cancellations")
+	return Stores{
+		Products: &mongoProducts{col: products},
+		Orders: &mongoOrders{
+			col:           database.Collection("orders"),
+			products:      products,
+			cancellations: cancellations,
+		},
+		Deliveries:    &mongoDeliveries{col: database.Collection("deliveries")},
+		Cancellations: &mongoCancellations{col: cancellations},
+		Carts:         &mongoCarts{col: database.Collection("carts")},
+		OrderFeed:     &mongoOrderFeed{stream: changeStream{col: database.Collection("orders")}},
+		ProductFeed:   &mongoProductFeed{stream: changeStream{col: products}},
+	}
+}
+
+// EnsureIndexes crea los índices que necesita la tienda (idempotente: se llama al arrancar).
+//   - carts.updated_at: TTL, Mongo borra solo los carritos abandonados.
+//   - products name/description: índice de texto para la búsqueda de la home (en español,
+//     el nombre pesa más que la descripción).
+func EnsureIndexes(ctx context.Context, database *mongo.Database) error {
+	_, err := database.Collection("carts").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}},
+		Options: options.Index().SetName("carts_ttl").SetExpireAfterSeconds(int32(CartTTL / time.Second)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("products").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+		Options: options.Index().
+			SetName("products_text").
+			SetDefaultLanguage("spanish").
+			SetWeights(bson.D{{Key: "name", Value: 3}, {Key: "description", Value: 1}}),
+	})
+	return err
+}
+
+// notFound traduce el "no hay documentos" del driver al error del paquete.
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// failed registra un error de Mongo con la colección y la operación que falló, y lo devuelve
+// sin tocar. Los resultados esperables (no encontrado, pedido no editable, falta de stock,
+// request cancelada por el cliente) no son fallas y no se registran.
+func failed(ctx context.Context, col *mongo.Collection, op string, err error) error {
+	var stockErr *StockError
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotEditable) ||
+		errors.As(err, &stockErr) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	slog.ErrorContext(ctx, "error de mongo", "collection", col.Name(), "op", op, "err", err)
+	return err
+}
+
+// ====== PRODUCTS ======
+
+type mongoProducts struct {
+	col *mongo.Collection // colección "products"
+}
+
+// productProjection: campos que usa la tienda (evita traer datos del admin que no mostramos).
+var productProjection = bson.M{
+	"_id":         1,
+	"name":        1,
+	"price":       1,
+	"description": 1,
+	"image_path":  1,
+	"is_active":   1,
+	"stock":       1,
+	"reserved":    1,
+	"category":    1,
+	"updated_at":  1,
+}
+
+func (s *mongoProducts) ListActive(ctx context.Context) ([]models.Product, error) {
+	cur, err := s.col.Find(ctx, bson.M{"is_active": true}, options.Find().SetProjection(productProjection))
+	if err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	defer cur.Close(ctx) // siempre cerrar el cursor
+
+	var products []models.Product
+	if err := cur.All(ctx, &products); err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	return products, nil
+}
+
+// catalogFilter arma el filtro de Search (siempre sólo activos).
+func catalogFilter(q CatalogQuery) bson.M {
+	filter := bson.M{"is_active": true}
+	if q.Category != "" {
+		filter["category"] = q.Category
+	}
+	if q.Text != "" {
+		filter["$text"] = bson.M{"$search": q.Text} // usa el índice de texto "products_text"
+	}
+	price := bson.M{}
+	if q.MinPrice > 0 {
+		price["$gte"] = q.MinPrice
+	}
+	if q.MaxPrice > 0 {
+		price["$lte"] = q.MaxPrice
+	}
+	if len(price) > 0 {
+		filter["price"] = price
+	}
+	if q.InStock {
+		// disponible = stock - reserved (mismo cálculo que la reserva del checkout)
+		filter["$expr"] = bson.M{"$gt": bson.A{
+			bson.M{"$subtract": bson.A{"$stock", bson.M{"$ifNull": bson.A{"$reserved", 0}}}},
+			0,
+		}}
+	}
+	return filter
+}
+
+func (s *mongoProducts) Search(ctx context.Context, q CatalogQuery) (CatalogPage, error) {
+	filter := catalogFilter(q)
+	total, err := s.col.CountDocuments(ctx, filter)
+	if err != nil {
+		return CatalogPage{}, failed(ctx, s.col, "count", err)
+	}
+
+	// _id al final desempata: sin orden total, skip/limit puede repetir o saltear productos
+	var sort bson.D
+	projection := productProjection
+	switch q.Sort {
+	case SortPriceAsc:
+		sort = bson.D{{Key: "price", Value: 1}}
+	case SortPriceDesc:
+		sort = bson.D{{Key: "price", Value: -1}}
+	case SortNewest:
+		sort = bson.D{{Key: "_id", Value: -1}} // el ObjectID lleva la fecha de alta
+	case SortRelevance:
+		if q.Text != "" {
+			// orden por puntaje de $text: hay que proyectarlo para poder ordenar por él
+			projection = bson.M{"score": bson.M{"$meta": "textScore"}}
+			for k, v := range productProjection {
+				projection[k] = v
+			}
+			sort = bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}
+			break
+		}
+		sort = bson.D{{Key: "name", Value: 1}}
+	default:
+		sort = bson.D{{Key: "name", Value: 1}}
+	}
+	if q.Sort != SortNewest {
+		sort = append(sort, bson.E{Key: "_id", Value: 1})
+	}
+
+	opts := options.Find().
+		SetProjection(projection).
+		SetSort(sort).
+		SetSkip(int64((q.Page - 1) * q.PageSize)).
+		SetLimit(int64(q.PageSize))
+	cur, err := s.col.Find(ctx, filter, opts)
+	if err != nil {
+		return CatalogPage{}, failed(ctx, s.col, "find", err)
+	}
+	defer cur.Close(ctx)
+
+	var products []models.Product
+	if err := cur.All(ctx, &products); err != nil {
+		return CatalogPage{}, failed(ctx, s.col, "find", err)
+	}
+	return CatalogPage{Products: products, Total: int(total)}, nil
+}
+
+func (s *mongoProducts) FindByID(ctx context.Context, id primitive.ObjectID) (models.Product, error) {
+	var p models.Product
+	err := s.col.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(productProjection)).Decode(&p)
+	return p, failed(ctx, s.col, "findOne", notFound(err))
+}
+
+func (s *mongoProducts) FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Product, error) {
+	out := make(map[primitive.ObjectID]models.Product, len(ids))
+	if len(ids) == 0 {
+		return out, nil // sin ids no hace falta ir a la DB
+	}
+	// Un solo round trip con $in (en vez de un FindOne por línea del carrito)
+	cur, err := s.col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(productProjection))
+	if err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	defer cur.Close(ctx)
+
+	var list []models.Product
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	for _, p := range list {
+		out[p.ID] = p
+	}
+	return out, nil
+}
+
+// ====== ORDERS ======
+
+type mongoOrders struct {
+	col           *mongo.Collection // colección "orders"
+	products      *mongo.Collection // "products": para reservar stock dentro de la transacción
+	cancellations *mongo.Collection // "cancellations": destino del snapshot al cancelar
+}
+
+// reserveStock intenta reservar qty unidades del producto dentro de la transacción (sc).
+// La reserva es un $inc sobre "reserved" condicionado a que stock - reserved >= qty,
+// así dos checkouts concurrentes no pueden reservar las mismas unidades.
+// Devuelve (true, 0) si reservó; (false, disponibles) si no alcanzó el stock.
+func (s *mongoOrders) reserveStock(sc mongo.SessionContext, oid primitive.ObjectID, qty int) (bool, int, error) {
+	// $expr permite comparar campos del mismo documento; $ifNull cubre productos sin "reserved"
+	filter := bson.M{
+		"_id":       oid,
+		"is_active": true,
+		"$expr": bson.M{"$gte": bson.A{
+			bson.M{"$subtract": bson.A{"$stock", bson.M{"$ifNull": bson.A{"$reserved", 0}}}},
+			qty,
+		}},
+	}
+	res, err := s.products.UpdateOne(sc, filter, bson.M{"$inc": bson.M{"reserved": qty}})
+	if err != nil {
+		return false, 0, err
+	}
+	if res.MatchedCount == 1 {
+		return true, 0, nil
+	}
+
+	// No matcheó: leemos stock/reserved para decirle al comprador cuántas unidades quedan
+	var p struct {
+		Stock    int `bson:"stock"`
+		Reserved int `bson:"reserved"`
+	}
+	if err := s.products.FindOne(sc, bson.M{"_id": oid}).Decode(&p); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return false, 0, err
+	}
+	available := p.Stock - p.Reserved
+	if available < 0 {
+		available = 0
+	}
+	return false, available, nil
+}
+
+func (s *mongoOrders) Create(ctx context.Context, order *models.Order) error {
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+
+	// TRANSACCIÓN: reservamos stock de cada línea e insertamos el pedido de forma atómica.
+	// Si alguna línea no se puede cubrir, abortamos y no queda ninguna reserva colgada.
+	session, err := s.col.Database().Client().StartSession()
+	if err != nil {
+		return failed(ctx, s.col, "startSession", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// WithTransaction puede reintentar el callback: cada intento arranca sin faltantes previos
+		var stockErr StockError
+		for _, it := range order.Items {
+			ok, available, err := s.reserveStock(sc, it.ProductID, it.Qty)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				stockErr.Shortages = append(stockErr.Shortages, Shortage{
+					ProductID: it.ProductID,
+					Name:      it.Name,
+					Requested: it.Qty,
+					Available: available,
+				})
+			}
+		}
+		if len(stockErr.Shortages) > 0 {
+			return nil, &stockErr // rollback de las reservas hechas en este intento
+		}
+		return s.col.InsertOne(sc, order)
+	})
+	return failed(ctx, s.col, "transaction:create", err)
+}
+
+func (s *mongoOrders) FindByID(ctx context.Context, id primitive.ObjectID) (models.Order, error) {
+	var o models.Order
+	err := s.col.FindOne(ctx, bson.M{"_id": id}).Decode(&o)
+	return o, failed(ctx, s.col, "findOne", notFound(err))
+}
+
+func (s *mongoOrders) List(ctx context.Context) ([]models.Order, error) {
+	cur, err := s.col.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	defer cur.Close(ctx)
+
+	var orders []models.Order
+	if err := cur.All(ctx, &orders); err != nil {
+		return nil, failed(ctx, s.col, "find", err)
+	}
+	return orders, nil
+}
+
+// releaseStock devuelve qty unidades reservadas del producto (sin bajar nunca de 0:
+// los pedidos anteriores a las reservas no habían reservado nada).
+func (s *mongoOrders) releaseStock(sc mongo.SessionContext, oid primitive.ObjectID, qty int) error {
+	// Update con pipeline: reserved = max(0, reserved - qty)
+	_, err := s.products.UpdateOne(sc, bson.M{"_id": oid}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"reserved": bson.M{"$max": bson.A{
+			0,
+			bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$reserved", 0}}, qty}},
+		}}}}},
+	})
+	return err
+}
+
+func (s *mongoOrders) Edit(ctx context.Context, order models.Order) error {
+	session, err := s.col.Database().Client().StartSession()
+	if err != nil {
+		return failed(ctx, s.col, "startSession", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// Leemos la versión actual dentro de la transacción (ítems ya reservados)
+		var current models.Order
+		if err := s.col.FindOne(sc, bson.M{"_id": order.ID}).Decode(&current); err != nil {
+			return nil, notFound(err)
+		}
+		if !current.Editable() {
+			return nil, ErrNotEditable
+		}
+
+		// Ajustamos reservas sólo por la diferencia: reservamos lo que se agregó y liberamos lo que se quitó
+		var stockErr StockError
+		for _, d := range itemDeltas(current.Items, order.Items) {
+			if d.New < d.Old {
+				if err := s.releaseStock(sc, d.ProductID, d.Old-d.New); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			ok, available, err := s.reserveStock(sc, d.ProductID, d.New-d.Old)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				stockErr.Shortages = append(stockErr.Shortages, Shortage{
+					ProductID: d.ProductID,
+					Name:      d.Name,
+					Requested: d.New,
+					Available: available + d.Old, // lo que ya tenía reservado este pedido también cuenta
+				})
+			}
+		}
+		if len(stockErr.Shortages) > 0 {
+			return nil, &stockErr // rollback: ninguna reserva ni liberación queda aplicada
+		}
+
+		// Compare-and-swap: sólo actualizamos si sigue en "nuevo". Si el admin lo cambió en paralelo,
+		// Mongo detecta el conflicto de escritura, reintenta la transacción y el chequeo de arriba falla.
+		res, err := s.col.UpdateOne(sc, bson.M{"_id": order.ID, "status": models.StatusNuevo}, bson.M{"$set": bson.M{
+			"buyer_name":   order.BuyerName,
+			"address":      order.Address,
+			"igloo_sector": order.IglooSector,
+			"items":        order.Items,
+			"total":        order.Total,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, ErrNotEditable
+		}
+		return nil, nil
+	})
+	return failed(ctx, s.col, "transaction:edit", err)
+}
+
+func (s *mongoOrders) Cancel(ctx context.Context, id primitive.ObjectID, reason string) (models.Cancellation, error) {
+	session, err := s.col.Database().Client().StartSession()
+	if err != nil {
+		return models.Cancellation{}, failed(ctx, s.col, "startSession", err)
+	}
+	defer session.EndSession(ctx)
+
+	res, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		var order models.Order
+		if err := s.col.FindOne(sc, bson.M{"_id": id}).Decode(&order); err != nil {
+			return nil, notFound(err)
+		}
+		if !order.Editable() {
+			return nil, ErrNotEditable
+		}
+
+		// Liberamos las reservas de cada ítem (las unidades vuelven a estar disponibles)
+		for _, it := range order.Items {
+			if err := s.releaseStock(sc, it.ProductID, it.Qty); err != nil {
+				return nil, err
+			}
+		}
+
+		// Snapshot en "cancellations" (como hace el admin con "deliveries" al entregar)
+		c := models.NewCancellation(order, reason, time.Now())
+		if _, err := s.cancellations.InsertOne(sc, c); err != nil {
+			return nil, err
+		}
+
+		// Borramos de "orders" sólo si sigue en "nuevo" (compare-and-swap, igual que Edit)
+		del, err := s.col.DeleteOne(sc, bson.M{"_id": id, "status": models.StatusNuevo})
+		if err != nil {
+			return nil, err
+		}
+		if del.DeletedCount == 0 {
+			return nil, ErrNotEditable
+		}
+		return c, nil
+	})
+	if err != nil {
+		return models.Cancellation{}, failed(ctx, s.col, "transaction:cancel", err)
+	}
+	return res.(models.Cancellation), nil
+}
+
+// ====== DELIVERIES ======
+
+type mongoDeliveries struct {
+	col *mongo.Collection // colección "deliveries"
+}
+
+func (s *mongoDeliveries) FindByOrderID(ctx context.Context, orderID primitive.ObjectID) (models.Delivery, error) {
+	var d models.Delivery
+	err := s.col.FindOne(ctx, bson.M{"order_id": orderID}).Decode(&d)
+	return d, failed(ctx, s.col, "findOne", notFound(err))
+}
+
+// ====== CANCELLATIONS ======
+
+type mongoCancellations struct {
+	col *mongo.Collection // colección "cancellations"
+}
+
+func (s *mongoCancellations) FindByOrderID(ctx context.Context, orderID primitive.ObjectID) (models.Cancellation, error) {
+	var c models.Cancellation
+	err := s.col.FindOne(ctx, bson.M{"order_id": orderID}).Decode(&c)
+	return c, failed(ctx, s.col, "findOne", notFound(err))
+}
+
+// ====== CARTS ======
+
+type mongoCarts struct {
+	col *mongo.Collection // colección "carts" (_id = id de sesión)
+}
+
+func (s *mongoCarts) Get(ctx context.Context, sessionID string) (models.Cart, error) {
+	var c models.Cart
+	err := s.col.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Cart{SessionID: sessionID, Items: map[string]int{}}, nil
+	}
+	if c.Items == nil {
+		c.Items = map[string]int{}
+	}
+	return c, failed(ctx, s.col, "findOne", err)
+}
+
+func (s *mongoCarts) SetQty(ctx context.Context, sessionID string, productID primitive.ObjectID, qty int) error {
+	// items es un subdocumento { <idHex>: qty }: se actualiza una línea sin leer el carrito entero
+	field := "items." + productID.Hex()
+	update := bson.M{"$set": bson.M{field: qty, "updated_at": time.Now()}}
+	if qty <= 0 {
+		update = bson.M{"$unset": bson.M{field: ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+	_, err := s.col.UpdateOne(ctx, bson.M{"_id": sessionID}, update, options.Update().SetUpsert(true))
+	return failed(ctx, s.col, "upsert", err)
+}
+
+func (s *mongoCarts) Clear(ctx context.Context, sessionID string) error {
+	_, err := s.col.DeleteOne(ctx, bson.M{"_id": sessionID})
+	return failed(ctx, s.col, "delete", err)
+}
+
+// ====== CHANGE STREAMS ======
+
+// changeStream: change stream sobre col que recuerda el resume token del último evento
+// entregado, para retomar después de un corte sin perder cambios. Un solo watch a la vez.
+type changeStream struct {
+	col    *mongo.Collection
+	resume bson.Raw // resume token del último cambio entregado (nil = desde ahora)
+}
+
+// changeEvent: los campos del evento del change stream que usamos (T = documento de la colección).
+type changeEvent[T any] struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *T `bson:"fullDocument"`
+}
+
+// watch abre el stream (retomando desde el token si hay), avisa a opened si retomó o no,
+// y llama a fn con el id y el documento de cada alta/cambio/baja hasta que ctx se cancele o
+// el stream se corte. Los updates traen el documento completo (updateLookup); si entre el
+// cambio y la lectura el documento ya se borró, doc viene nil igual que en una baja.
+func watch[T any](ctx context.Context, c *changeStream, opened func(resumed bool), fn func(id primitive.ObjectID, doc *T)) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	resumed := c.resume != nil
+	if resumed {
+		opts.SetResumeAfter(c.resume)
+	}
+	cs, err := c.col.Watch(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}},
+	}, opts)
+	if err != nil {
+		c.forgetLostToken(err)
+		return err
+	}
+	defer cs.Close(context.Background())
+	if opened != nil {
+		opened(resumed)
+	}
+
+	for cs.Next(ctx) {
+		var ev changeEvent[T]
+		if err := cs.Decode(&ev); err != nil {
+			return err
+		}
+		doc := ev.FullDocument
+		if ev.OperationType == "delete" {
+			doc = nil
+		}
+		fn(ev.DocumentKey.ID, doc)
+		c.resume = cs.ResumeToken()
+	}
+	c.forgetLostToken(cs.Err())
+	return cs.Err()
+}
+
+// Códigos de error del servidor para un resume token que ya no sirve.
+const (
+	codeInvalidResumeToken      = 260 // el token no es válido para esta colección/stream
+	codeChangeStreamHistoryLost = 286 // el oplog ya no tiene el punto del token (venció)
+)
+
+// forgetLostToken descarta el resume token sólo si el servidor dice que no se puede retomar
+// desde él: la próxima vez el stream arranca de cero (y el cache recarga todo). Ante cualquier
+// otro error (red, elección de primario, timeout) lo conservamos para retomar sin perder nada.
+func (c *changeStream) forgetLostToken(err error) {
+	var se mongo.ServerError
+	if errors.As(err, &se) && (se.HasErrorCode(codeInvalidResumeToken) || se.HasErrorCode(codeChangeStreamHistoryLost)) {
+		c.resume = nil
+	}
+}
+
+// ====== ORDER FEED ======
+
+type mongoOrderFeed struct{ stream changeStream } // sobre "orders"
+
+// Watch sigue los cambios de "orders" (ver OrderFeed).
+func (f *mongoOrderFeed) Watch(ctx context.Context, fn func(OrderChange)) error {
+	return watch(ctx, &f.stream, nil, func(id primitive.ObjectID, o *models.Order) {
+		fn(OrderChange{OrderID: id, Order: o})
+	})
+}
+
+// ====== PRODUCT FEED ======
+
+type mongoProductFeed struct{ stream changeStream } // sobre "products"
+
+// Watch sigue los cambios de "products" (ver ProductFeed).
+func (f *mongoProductFeed) Watch(ctx context.Context, fn func(ProductChange)) error {
+	opened := func(resumed bool) { fn(ProductChange{Opened: true, Resumed: resumed}) }
+	return watch(ctx, &f.stream, opened, func(id primitive.ObjectID, p *models.Product) {
+		fn(ProductChange{ProductID: id, Product: p})
+	})
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestForgetLostToken(t *testing.T) {
+	token, err := bson.Marshal(bson.D{{Key: "_data", Value: "8263"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		err  error
+		keep bool
+	}{
+		{"historia perdida", mongo.CommandError{Code: codeChangeStreamHistoryLost}, false},
+		{"token inválido", fmt.Errorf("watch: %w", mongo.CommandError{Code: codeInvalidResumeToken}), false},
+		{"elección de primario", mongo.CommandError{Code: 10107, Name: "NotWritablePrimary"}, true},
+		{"red", errors.New("connection reset by peer"), true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"sin error", nil, true},
+	}
+	for _, tc := range cases {
+		c := &changeStream{resume: bson.Raw(token)}
+		c.forgetLostToken(tc.err)
+		if kept := c.resume != nil; kept != tc.keep {
+			t.Errorf("%s: conservó el token = %v, quiero %v", tc.name, kept, tc.keep)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// search.go — búsqueda del catálogo sobre productos en memoria
+// La usan el store en memoria (tests) y el cache del catálogo (internal/cache), así las dos
+// filtran, ordenan y paginan igual.
+
+package store
+
+import (
+	"sort"    // orden de los resultados
+	"strings" // búsqueda de texto
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchProducts replica el filtro de Mongo sobre products (sólo los activos cuentan).
+// La búsqueda de texto es "contiene alguna palabra" (sin mayúsculas) sobre nombre y
+// descripción, y la relevancia es la cantidad de palabras encontradas (Mongo usa el índice
+// de texto en español, con raíces: puede encontrar algo más).
+func SearchProducts(products []models.Product, q CatalogQuery) CatalogPage {
+	terms := strings.Fields(strings.ToLower(q.Text))
+	score := map[primitive.ObjectID]int{}
+	var matched []models.Product
+	for _, p := range products {
+		if !p.Active ||
+			(q.Category != "" && p.Category != q.Category) ||
+			(q.MinPrice > 0 && p.Price < q.MinPrice) ||
+			(q.MaxPrice > 0 && p.Price > q.MaxPrice) ||
+			(q.InStock && p.SoldOut()) {
+			continue
+		}
+		if len(terms) > 0 {
+			text := strings.ToLower(p.Name + " " + p.Description)
+			for _, t := range terms {
+				if strings.Contains(text, t) {
+					score[p.ID]++
+				}
+			}
+			if score[p.ID] == 0 {
+				continue
+			}
+		}
+		matched = append(matched, p)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		switch {
+		case q.Sort == SortPriceAsc && a.Price != b.Price:
+			return a.Price < b.Price
+		case q.Sort == SortPriceDesc && a.Price != b.Price:
+			return a.Price > b.Price
+		case q.Sort == SortNewest:
+			return a.ID.Hex() > b.ID.Hex()
+		case q.Sort == SortRelevance && len(terms) > 0 && score[a.ID] != score[b.ID]:
+			return score[a.ID] > score[b.ID]
+		case (q.Sort == SortName || (q.Sort == SortRelevance && len(terms) == 0)) && a.Name != b.Name:
+			return a.Name < b.Name
+		}
+		return a.ID.Hex() < b.ID.Hex()
+	})
+
+	page := CatalogPage{Total: len(matched)}
+	from := (q.Page - 1) * q.PageSize
+	if from >= 0 && from < len(matched) { // from < 0: página inválida (o desbordada) → página vacía
+		page.Products = matched[from:min(from+q.PageSize, len(matched))]
+	}
+	return page
+}
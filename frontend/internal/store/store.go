@@ -0,0 +1,220 @@
+// store.go — Capa de repositorios entre los handlers y MongoDB
+// Los handlers dependen sólo de estas interfaces; hay una implementación
+// sobre Mongo (mongo.go) y otra en memoria (memory.go) para tests con httptest.
+
+package store
+
+import (
+	"context" // context.Context: timeouts/cancelación que vienen desde la request
+	"errors"  // errors.New: errores centinela del paquete
+	"fmt"     // fmt: armar el mensaje de StockError
+	"sort"    // sort.Slice: orden determinístico de los deltas
+	"strings" // strings.Join: unir los faltantes en un solo mensaje
+
+	"github.com/gastonduartem/Challenge-1/frontend/internal/models" // tipos de dominio
+	"go.mongodb.org/mongo-driver/bson/primitive"                    // ObjectID de Mongo
+)
+
+// ErrNotFound: el documento pedido no existe (equivale a mongo.ErrNoDocuments).
+var ErrNotFound = errors.New("store: documento no encontrado")
+
+// ErrNotEditable: el pedido existe pero ya no está en "nuevo" (el admin lo empezó a preparar).
+var ErrNotEditable = errors.New("store: el pedido ya no se puede modificar")
+
+// Shortage describe una línea del pedido que no se pudo reservar por falta de stock.
+type Shortage struct {
+	ProductID primitive.ObjectID // producto sin stock suficiente
+	Name      string             // nombre (snapshot del ítem) para mostrar al comprador
+	Requested int                // cantidad pedida
+	Available int                // unidades que quedaban libres (stock - reserved)
+}
+
+// StockError: OrderStore.Create lo devuelve cuando al menos una línea no tiene stock.
+// En ese caso no se reservó nada ni se insertó el pedido.
+type StockError struct {
+	Shortages []Shortage
+}
+
+// Error implementa la interfaz error.
+func (e *StockError) Error() string {
+	parts := make([]string, 0, len(e.Shortages))
+	for _, s := range e.Shortages {
+		parts = append(parts, fmt.Sprintf("%s (pedido %d, disponible %d)", s.Name, s.Requested, s.Available))
+	}
+	return "stock insuficiente: " + strings.Join(parts, ", ")
+}
+
+// CatalogSort: orden del catálogo en la home.
+type CatalogSort string
+
+const (
+	SortRelevance CatalogSort = ""           // relevancia del texto buscado (sin búsqueda: por nombre)
+	SortName      CatalogSort = "name"       // nombre A→Z
+	SortPriceAsc  CatalogSort = "price_asc"  // más baratos primero
+	SortPriceDesc CatalogSort = "price_desc" // más caros primero
+	SortNewest    CatalogSort = "newest"     // últimos cargados primero
+)
+
+// Valid indica si s es uno de los órdenes conocidos.
+func (s CatalogSort) Valid() bool {
+	switch s {
+	case SortRelevance, SortName, SortPriceAsc, SortPriceDesc, SortNewest:
+		return true
+	}
+	return false
+}
+
+// CatalogQuery: filtros, orden y página de la búsqueda del catálogo (sólo productos activos).
+type CatalogQuery struct {
+	Category string      // slug de categoría (vacío = todas)
+	Text     string      // búsqueda de texto en nombre y descripción (vacío = sin búsqueda)
+	MinPrice int         // precio mínimo (0 = sin mínimo)
+	MaxPrice int         // precio máximo (0 = sin máximo)
+	InStock  bool        // sólo productos con unidades disponibles (stock - reserved > 0)
+	Sort     CatalogSort // orden
+	Page     int         // página, desde 1
+	PageSize int         // productos por página
+}
+
+// CatalogPage: una página de resultados + el total de productos que cumplen los filtros.
+type CatalogPage struct {
+	Products []models.Product
+	Total    int
+}
+
+// ProductStore: lectura del catálogo ("products").
+type ProductStore interface {
+	// ListActive devuelve los productos con is_active = true.
+	ListActive(ctx context.Context) ([]models.Product, error)
+	// Search devuelve una página de productos activos que cumplen q.
+	Search(ctx context.Context, q CatalogQuery) (CatalogPage, error)
+	// FindByID busca un producto por _id; ErrNotFound si no existe.
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Product, error)
+	// FindByIDs busca varios productos en una sola consulta (activos o no).
+	// Los ids que no existen simplemente no aparecen en el map.
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Product, error)
+}
+
+// OrderStore: pedidos activos ("orders").
+type OrderStore interface {
+	// Create reserva el stock de cada ítem e inserta el pedido de forma atómica.
+	// Completa order.ID. Si falta stock devuelve *StockError y no persiste nada.
+	Create(ctx context.Context, order *models.Order) error
+	// FindByID busca un pedido activo por _id; ErrNotFound si no existe.
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Order, error)
+	// List devuelve todos los pedidos activos.
+	List(ctx context.Context) ([]models.Order, error)
+	// Edit reemplaza buyer_name, address, igloo_sector, items y total del pedido order.ID,
+	// ajustando las reservas de stock por la diferencia con los ítems anteriores.
+	// El cambio es compare-and-swap sobre status = "nuevo": si el admin ya lo movió
+	// devuelve ErrNotEditable. Si falta stock devuelve *StockError y no cambia nada.
+	Edit(ctx context.Context, order models.Order) error
+	// Cancel mueve el pedido a "cancellations" (snapshot con motivo y fecha), libera sus
+	// reservas de stock y lo borra de "orders", todo en una transacción. Sólo se permite
+	// mientras status = "nuevo"; si no, ErrNotEditable.
+	Cancel(ctx context.Context, id primitive.ObjectID, reason string) (models.Cancellation, error)
+}
+
+// DeliveryStore: histórico de pedidos entregados ("deliveries").
+type DeliveryStore interface {
+	// FindByOrderID busca la entrega de un pedido; ErrNotFound si no existe.
+	FindByOrderID(ctx context.Context, orderID primitive.ObjectID) (models.Delivery, error)
+}
+
+// CancellationStore: histórico de pedidos cancelados por el comprador ("cancellations").
+type CancellationStore interface {
+	// FindByOrderID busca la cancelación de un pedido; ErrNotFound si no existe.
+	FindByOrderID(ctx context.Context, orderID primitive.ObjectID) (models.Cancellation, error)
+}
+
+// CartStore: carritos de compras por sesión ("carts").
+type CartStore interface {
+	// Get devuelve el carrito de la sesión; si no existe, uno vacío (sin error).
+	Get(ctx context.Context, sessionID string) (models.Cart, error)
+	// SetQty fija la cantidad de un producto (qty <= 0 lo quita) y crea el carrito si hace falta.
+	SetQty(ctx context.Context, sessionID string, productID primitive.ObjectID, qty int) error
+	// Clear borra el carrito (después de un checkout exitoso).
+	Clear(ctx context.Context, sessionID string) error
+}
+
+// OrderChange: un cambio en "orders" (pedido nuevo, editado, cambio de estado o baja).
+type OrderChange struct {
+	OrderID primitive.ObjectID
+	Order   *models.Order // estado actual; nil si el pedido salió de "orders" (entregado o cancelado)
+}
+
+// OrderFeed: cambios en vivo de "orders" (para el tablero y la página de estado por SSE).
+type OrderFeed interface {
+	// Watch llama a fn por cada cambio, en orden, hasta que ctx se cancele o se corte
+	// el stream (devuelve el error). fn no debe bloquear. Si se vuelve a llamar después
+	// de un corte, retoma desde el último cambio entregado cuando se puede.
+	Watch(ctx context.Context, fn func(OrderChange)) error
+}
+
+// ProductChange: un cambio en "products" (alta, edición, stock/reservas, baja), o el aviso de
+// que el stream (re)abrió.
+type ProductChange struct {
+	ProductID primitive.ObjectID
+	Product   *models.Product // estado actual (activo o no); nil si se borró
+	Opened    bool            // el stream (re)abrió: no trae producto
+	Resumed   bool            // con Opened: retomó desde el último cambio entregado (false = pudo perderse alguno)
+}
+
+// ProductFeed: cambios en vivo de "products" (para el cache del catálogo).
+type ProductFeed interface {
+	// Watch llama a fn primero con Opened = true y después por cada cambio, en orden, hasta
+	// que ctx se cancele o se corte el stream (devuelve el error). Los cambios esperan a que
+	// fn termine. Si se vuelve a llamar después de un corte, retoma desde el último cambio
+	// entregado cuando se puede (Resumed = true).
+	Watch(ctx context.Context, fn func(ProductChange)) error
+}
+
+// Stores agrupa los repositorios que se inyectan en los handlers desde main.
+type Stores struct {
+	Products      ProductStore
+	Orders        OrderStore
+	Deliveries    DeliveryStore
+	Cancellations CancellationStore
+	Carts         CartStore
+	OrderFeed     OrderFeed
+	ProductFeed   ProductFeed
+}
+
+// itemDelta: cambio de cantidad de un producto entre la versión vieja y la nueva de un pedido.
+type itemDelta struct {
+	ProductID primitive.ObjectID
+	Name      string // nombre del ítem nuevo (o del viejo si se quitó)
+	Old       int    // cantidad que ya tenía reservada el pedido
+	New       int    // cantidad pedida ahora
+}
+
+// itemDeltas compara dos listas de ítems y devuelve los productos cuya cantidad cambió,
+// ordenados por ID para que las reservas se hagan siempre en el mismo orden.
+func itemDeltas(oldItems, newItems []models.Item) []itemDelta {
+	byID := map[primitive.ObjectID]*itemDelta{}
+	get := func(it models.Item) *itemDelta {
+		d, ok := byID[it.ProductID]
+		if !ok {
+			d = &itemDelta{ProductID: it.ProductID, Name: it.Name}
+			byID[it.ProductID] = d
+		}
+		return d
+	}
+	for _, it := range oldItems {
+		get(it).Old += it.Qty
+	}
+	for _, it := range newItems {
+		d := get(it)
+		d.New += it.Qty
+		d.Name = it.Name
+	}
+
+	out := make([]itemDelta, 0, len(byID))
+	for _, d := range byID {
+		if d.New != d.Old {
+			out = append(out, *d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ProductID.Hex() < out[j].ProductID.Hex() })
+	return out
+}
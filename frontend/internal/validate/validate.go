@@ -0,0 +1,124 @@
+// validate.go — Reglas de validación de los forms de la tienda (checkout y edición)
+// Cada regla devuelve nil o un error con un mensaje listo para mostrar al comprador;
+// Errors junta esos mensajes por campo para volver a renderizar el form.
+
+package validate
+
+import (
+	"errors"       // errors.New: mensajes de las reglas
+	"fmt"          // fmt.Errorf: mensajes con límites
+	"math"         // math.MaxInt: detectar overflow en precios × cantidades
+	"net/mail"     // mail.ParseAddress: sintaxis de email (RFC 5322)
+	"strings"      // strings.TrimSpace / Contains
+	"unicode/utf8" // utf8.RuneCountInString: largo en caracteres, no en bytes
+)
+
+// Límites de los campos del comprador (en caracteres).
+const (
+	MaxNameLen    = 80  // buyer_name
+	MaxAddressLen = 160 // address
+	MaxEmailLen   = 254 // email (límite práctico de una dirección SMTP)
+	MinQty        = 1   // cantidad mínima por línea
+	MaxQty        = 99  // cantidad máxima por línea: nadie carga 100 pescados en un trineo
+)
+
+// IglooSectors: sectores válidos para igloo_sector (vacío = sin sector, es opcional).
+var IglooSectors = []string{"norte", "sur", "este", "oeste", "centro"}
+
+// Errors: mensajes de error por campo del form (nombre del input → mensaje).
+// Se guarda sólo el primer error de cada campo.
+type Errors map[string]string
+
+// Check registra err (si no es nil) como error del campo field.
+func (e Errors) Check(field string, err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := e[field]; !ok {
+		e[field] = err.Error()
+	}
+}
+
+// Any indica si hay al menos un error.
+func (e Errors) Any() bool { return len(e) > 0 }
+
+// Text valida un texto obligatorio de entre 1 y max caracteres (ya recortado con TrimSpace).
+func Text(label, v string, max int) error {
+	if v == "" {
+		return fmt.Errorf("%s es obligatorio.", label)
+	}
+	if utf8.RuneCountInString(v) > max {
+		return fmt.Errorf("%s no puede tener más de %d caracteres.", label, max)
+	}
+	return nil
+}
+
+// Email valida que v sea una dirección de email simple (usuario@dominio), sin nombre ni <>.
+func Email(v string) error {
+	if v == "" {
+		return errors.New("El email es obligatorio.")
+	}
+	if utf8.RuneCountInString(v) > MaxEmailLen {
+		return fmt.Errorf("El email no puede tener más de %d caracteres.", MaxEmailLen)
+	}
+	addr, err := mail.ParseAddress(v)
+	// ParseAddress acepta "Nombre <a@b>": exigimos que lo escrito sea sólo la dirección
+	if err != nil || addr.Address != v || addr.Name != "" {
+		return errors.New("El email no es válido.")
+	}
+	// Dominio con al menos un punto (pingu@localhost no nos sirve para avisar del pedido)
+	at := strings.LastIndex(v, "@")
+	if domain := v[at+1:]; !strings.Contains(domain, ".") || strings.HasSuffix(domain, ".") {
+		return errors.New("El email no es válido.")
+	}
+	return nil
+}
+
+// IglooSector valida que v sea vacío o uno de IglooSectors.
+func IglooSector(v string) error {
+	if v == "" {
+		return nil
+	}
+	for _, s := range IglooSectors {
+		if v == s {
+			return nil
+		}
+	}
+	return errors.New("Elegí un sector de la lista.")
+}
+
+// Quantity valida una cantidad por línea (entre MinQty y MaxQty).
+func Quantity(qty int) error {
+	if qty < MinQty || qty > MaxQty {
+		return fmt.Errorf("La cantidad tiene que estar entre %d y %d.", MinQty, MaxQty)
+	}
+	return nil
+}
+
+// ErrOverflow: el total no entra en un int (precio × cantidad o suma de subtotales).
+var ErrOverflow = errors.New("El total del pedido es demasiado grande.")
+
+// errNegative: precio, cantidad o subtotal negativo (nunca viene de un form válido).
+var errNegative = errors.New("precio y cantidad no pueden ser negativos")
+
+// Subtotal devuelve price × qty, o ErrOverflow si el producto no entra en un int.
+func Subtotal(price, qty int) (int, error) {
+	if price < 0 || qty < 0 {
+		return 0, errNegative
+	}
+	if qty != 0 && price > math.MaxInt/qty {
+		return 0, ErrOverflow
+	}
+	return price * qty, nil
+}
+
+// Add devuelve a + b (ambos >= 0), o ErrOverflow si la suma no entra en un int.
+func Add(a, b int) (int, error) {
+	if a < 0 || b < 0 {
+		return 0, errNegative // con a < 0, math.MaxInt-a desbordaría
+	}
+	if b > math.MaxInt-a {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
@@ -0,0 +1,88 @@
+package validate
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestQuantity(t *testing.T) {
+	for _, tc := range []struct {
+		qty int
+		ok  bool
+	}{{-1, false}, {0, false}, {1, true}, {99, true}, {100, false}, {math.MaxInt, false}} {
+		if err := Quantity(tc.qty); (err == nil) != tc.ok {
+			t.Errorf("Quantity(%d) = %v, quiero ok=%v", tc.qty, err, tc.ok)
+		}
+	}
+}
+
+func TestSubtotal(t *testing.T) {
+	for _, tc := range []struct {
+		price, qty, want int
+		err              error // nil = sin error; errNegative / ErrOverflow
+	}{
+		{5000, 3, 15000, nil},
+		{5000, 0, 0, nil},
+		{math.MaxInt / 99, 99, math.MaxInt / 99 * 99, nil}, // justo en el límite
+		{math.MaxInt/99 + 1, 99, 0, ErrOverflow},
+		{math.MaxInt, 1, math.MaxInt, nil},
+		{math.MaxInt, 2, 0, ErrOverflow},
+		{-1, 3, 0, errNegative},
+		{5000, -3, 0, errNegative},
+	} {
+		got, err := Subtotal(tc.price, tc.qty)
+		if !errors.Is(err, tc.err) || got != tc.want {
+			t.Errorf("Subtotal(%d, %d) = %d, %v; quiero %d, %v", tc.price, tc.qty, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, want int
+		err        error
+	}{
+		{1, 2, 3, nil},
+		{math.MaxInt - 1, 1, math.MaxInt, nil},
+		{math.MaxInt, 1, 0, ErrOverflow},
+		{math.MaxInt, math.MaxInt, 0, ErrOverflow},
+		{-1, 2, 0, errNegative},
+		{2, -1, 0, errNegative},
+	} {
+		got, err := Add(tc.a, tc.b)
+		if !errors.Is(err, tc.err) || got != tc.want {
+			t.Errorf("Add(%d, %d) = %d, %v; quiero %d, %v", tc.a, tc.b, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	for _, tc := range []struct {
+		email string
+		ok    bool
+	}{
+		{"pingu@polo.sur", true},
+		{"", false},
+		{"pingu", false},
+		{"a@b", false},
+		{"a@b.", false},
+		{"Pingu <pingu@polo.sur>", false},
+		{" pingu@polo.sur", false},
+	} {
+		if err := Email(tc.email); (err == nil) != tc.ok {
+			t.Errorf("Email(%q) = %v, quiero ok=%v", tc.email, err, tc.ok)
+		}
+	}
+}
+
+func TestIglooSector(t *testing.T) {
+	for _, tc := range []struct {
+		sector string
+		ok     bool
+	}{{"", true}, {"norte", true}, {"centro", true}, {"Norte", false}, {"polo", false}} {
+		if err := IglooSector(tc.sector); (err == nil) != tc.ok {
+			t.Errorf("IglooSector(%q) = %v, quiero ok=%v", tc.sector, err, tc.ok)
+		}
+	}
+}